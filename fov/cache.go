@@ -0,0 +1,126 @@
+package fov
+
+import "container/list"
+
+// cache is a least recently used cache of visible sets, keyed by the position and radius they were computed for
+type cache struct {
+	size int
+	// order holds every entry from the most recently used at the front to the least recently used at the back
+	order   *list.List
+	entries map[cacheKey]*list.Element
+}
+
+// cacheKey identifies the compute that produced a cached visible set
+type cacheKey struct {
+	x, y, radius int
+}
+
+// cacheEntry is a single visible set in the cache, the value of each element of cache.order. distances holds the
+// distance of each of its cells when the View records them, see WithDistances
+type cacheEntry struct {
+	key       cacheKey
+	visible   CellSet
+	distances map[int64]int
+}
+
+// newCache returns an empty cache holding at most size visible sets
+func newCache(size int) *cache {
+	return &cache{size: size, order: list.New(), entries: make(map[cacheKey]*list.Element)}
+}
+
+// WithCache enables ComputeCached, remembering the visible sets of up to size different positions and radii. Once
+// the cache is full, the set that went unused the longest is dropped to make room. A size of 0 or less leaves the
+// cache disabled
+func WithCache(size int) Option {
+	return func(v *View) {
+		if size > 0 {
+			v.cache = newCache(size)
+		}
+	}
+}
+
+// ComputeCached is Compute for maps whose walls rarely change, such as a puzzle where the player keeps coming back
+// to the same tiles. When the View was created WithCache and the same position and radius have been computed before,
+// the visible set is simply restored from the cache rather than computed again. Only the visible set is cached, along
+// with the distances of WithDistances, so this is only equivalent to Compute, and not to any of its variations, and
+// the cache knows nothing about the grid. Whenever a wall changes the cache has to be told with InvalidateCache, and
+// a View should only ever be used with one grid. Without a cache this is just Compute
+func (v *View) ComputeCached(grid GridMap, px, py, radius int) *View {
+	c := v.cache
+	if c == nil {
+		return v.Compute(grid, px, py, radius)
+	}
+	k := cacheKey{px, py, radius}
+	if e, ok := c.entries[k]; ok {
+		c.order.MoveToFront(e)
+		v.Reset()
+		v.originX, v.originY, v.radius = px, py, radius
+		entry := e.Value.(*cacheEntry)
+		for cell := range entry.visible {
+			if v.insert(cell) && v.distances != nil {
+				v.distances[cell] = entry.distances[cell]
+			}
+		}
+		v.memorize()
+		return v
+	}
+
+	v.Compute(grid, px, py, radius)
+	var entry *cacheEntry
+	if c.order.Len() >= c.size {
+		// Recycle the least recently used entry, storage and all
+		e := c.order.Back()
+		entry = e.Value.(*cacheEntry)
+		delete(c.entries, entry.key)
+		c.order.Remove(e)
+		entry.visible.clear()
+		for cell := range entry.distances {
+			delete(entry.distances, cell)
+		}
+	} else {
+		entry = &cacheEntry{visible: make(CellSet, len(v.Visible))}
+	}
+	entry.key = k
+	for cell := range v.Visible {
+		entry.visible[cell] = struct{}{}
+	}
+	if v.distances != nil {
+		if entry.distances == nil {
+			entry.distances = make(map[int64]int, len(v.distances))
+		}
+		for cell, d := range v.distances {
+			entry.distances[cell] = d
+		}
+	}
+	c.entries[k] = c.order.PushFront(entry)
+	return v
+}
+
+// InvalidateCache empties the cache of ComputeCached, which has to be done whenever the walls of the grid change
+func (v *View) InvalidateCache() {
+	if v.cache == nil {
+		return
+	}
+	v.cache.order.Init()
+	for k := range v.cache.entries {
+		delete(v.cache.entries, k)
+	}
+}
+
+// InvalidateAt drops the cached visible sets that the tile at x,y may have been part of, for when a single tile
+// changes, such as a door being opened, while the rest of the cache stays. A compute never looks at anything further
+// from the player than its radius along either axis, so every cached set whose square of that size contains x,y is
+// dropped, whether or not x,y itself was visible. That is more than strictly necessary, but a tile that wasn't visible
+// can still have cast a shadow the visible set depends on. On an IndexedGrid a single tile can be reached from far
+// away by wrapping around, so use InvalidateCache there instead
+func (v *View) InvalidateAt(x, y int) {
+	if v.cache == nil {
+		return
+	}
+	for k, e := range v.cache.entries {
+		if abs(x-k.x) <= k.radius && abs(y-k.y) <= k.radius {
+			delete(v.cache.entries, k)
+			v.cache.order.Remove(e)
+		}
+	}
+}
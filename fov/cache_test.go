@@ -0,0 +1,78 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComputeCachedRestoresDistances(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 30, 30, 6)
+	v := New(WithCache(4), WithDistances())
+	want := New(WithDistances()).Compute(g, 10, 12, 7)
+	for i := 0; i < 2; i++ {
+		// The first compute is a miss and the second a hit, in between a different position takes over the View
+		v.ComputeCached(g, 10, 12, 7)
+		sameVisible(t, v, want, "compute %d", i)
+		for k := range want.Visible {
+			x, y := unpack(k)
+			got, ok := v.DistanceOf(x, y)
+			if d, _ := want.DistanceOf(x, y); !ok || got != d {
+				t.Fatalf("compute %d: %d,%d is at distance %d (%v), want %d", i, x, y, got, ok, d)
+			}
+		}
+		v.ComputeCached(g, 20, 20, 5)
+	}
+}
+
+func TestComputeCachedDropsLeastRecentlyUsed(t *testing.T) {
+	g := NewBoolGrid(40, 40)
+	v := New(WithCache(2))
+	v.ComputeCached(g, 5, 5, 4)
+	v.ComputeCached(g, 20, 20, 4)
+	v.ComputeCached(g, 32, 32, 4)
+	// Walls that nothing was told about, so only a set that is computed again sees them
+	g.SetOpaque(6, 5, true)
+	g.SetOpaque(21, 20, true)
+
+	stale := New().Compute(NewBoolGrid(40, 40), 20, 20, 4)
+	sameVisible(t, v.ComputeCached(g, 20, 20, 4), stale, "20,20 should still be cached")
+	sameVisible(t, v.ComputeCached(g, 5, 5, 4), New().Compute(g, 5, 5, 4), "5,5 should have been dropped")
+	// 32,32 was the least recently used when 5,5 made room, while 20,20 was used more recently
+	sameVisible(t, v.ComputeCached(g, 20, 20, 4), stale, "20,20 should still be cached after 5,5 came back")
+	if len(v.cache.entries) != 2 || v.cache.order.Len() != 2 {
+		t.Errorf("the cache holds %d sets, want its size of 2", len(v.cache.entries))
+	}
+	if _, ok := v.cache.entries[cacheKey{32, 32, 4}]; ok {
+		t.Error("32,32 should have been dropped to make room for 5,5")
+	}
+}
+
+func TestInvalidateAt(t *testing.T) {
+	g, old := NewBoolGrid(40, 40), NewBoolGrid(40, 40)
+	origins := []Point{{8, 8}, {12, 10}, {30, 30}, {30, 8}}
+	v := New(WithCache(len(origins)))
+	for _, p := range origins {
+		v.ComputeCached(g, p.X, p.Y, 5)
+	}
+	// A door closing within the squares of 8,8 and 12,10, which the cache is told about, and walls right next to the
+	// other two that it isn't told about, so that only sets which are computed again see them
+	g.SetOpaque(10, 9, true)
+	v.InvalidateAt(10, 9)
+	g.SetOpaque(31, 30, true)
+	g.SetOpaque(30, 9, true)
+	for _, p := range origins[:2] {
+		sameVisible(t, v.ComputeCached(g, p.X, p.Y, 5), New().Compute(g, p.X, p.Y, 5), "%d,%d after the door closed",
+			p.X, p.Y)
+	}
+	for _, p := range origins[2:] {
+		sameVisible(t, v.ComputeCached(g, p.X, p.Y, 5), New().Compute(old, p.X, p.Y, 5),
+			"%d,%d should still be served from the cache", p.X, p.Y)
+	}
+	// Opening the door again is just as much of a change
+	g.SetOpaque(10, 9, false)
+	v.InvalidateAt(10, 9)
+	for _, p := range origins[:2] {
+		sameVisible(t, v.ComputeCached(g, p.X, p.Y, 5), New().Compute(g, p.X, p.Y, 5), "%d,%d after the door opened",
+			p.X, p.Y)
+	}
+}
@@ -0,0 +1,132 @@
+package fov
+
+import "sort"
+
+// CellsByDistance returns the visible cells ordered from nearest to furthest from px,py, as measured by the View's
+// Metric or DistanceFunc, which suits effects that reveal the field of view outward in rings. Cells at the same
+// distance are ordered by Y and then X, so the order is always the same for the same visible set
+func (v *View) CellsByDistance(px, py int) []Point {
+	type ranked struct {
+		p    Point
+		dist float64
+	}
+	s := scan{px: px, py: py, metric: v.metric, distance: v.distance}
+	r := make([]ranked, 0, len(v.Visible))
+	for k := range v.Visible {
+		x, y := unpack(k)
+		r = append(r, ranked{Point{x, y}, s.dist(x-px, y-py)})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		a, b := r[i], r[j]
+		if a.dist != b.dist {
+			return a.dist < b.dist
+		}
+		if a.p.Y != b.p.Y {
+			return a.p.Y < b.p.Y
+		}
+		return a.p.X < b.p.X
+	})
+	cells := make([]Point, len(r))
+	for i := range r {
+		cells[i] = r[i].p
+	}
+	return cells
+}
+
+// DistanceOf returns how far the visible tile at x,y is from the player, as measured by the most recent compute, for
+// Views created WithDistances. The distance is the smallest radius that would have reached the tile, so straight
+// line distances are rounded up, e.g. a tile one step away diagonally is at distance 2. ok is false for tiles that
+// aren't visible, or when distances aren't being recorded
+func (v *View) DistanceOf(x, y int) (dist int, ok bool) {
+	dist, ok = v.distances[key(x, y)]
+	return dist, ok
+}
+
+// Spiral returns the visible cells in the order of a square spiral winding clockwise outward from px,py, for
+// revealing the field of view with a sweeping animation. Each ring of the spiral holds the cells at the same
+// Chebyshev distance and starts from the cell straight north of px,py. Cells that aren't visible are simply skipped
+func (v *View) Spiral(px, py int) []Point {
+	type ranked struct {
+		p          Point
+		ring, step int
+	}
+	r := make([]ranked, 0, len(v.Visible))
+	for k := range v.Visible {
+		x, y := unpack(k)
+		ring, step := spiralStep(x-px, y-py)
+		r = append(r, ranked{Point{x, y}, ring, step})
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].ring != r[j].ring {
+			return r[i].ring < r[j].ring
+		}
+		return r[i].step < r[j].step
+	})
+	cells := make([]Point, len(r))
+	for i := range r {
+		cells[i] = r[i].p
+	}
+	return cells
+}
+
+// spiralStep returns which ring of the spiral the offset dx,dy lies on and how many steps clockwise from north it
+// is along that ring
+func spiralStep(dx, dy int) (ring, step int) {
+	ring = max(abs(dx), abs(dy))
+	switch {
+	case dy == -ring && dx >= 0:
+		// The right half of the top edge, up to and including the north east corner
+		return ring, dx
+	case dx == ring:
+		return ring, 2*ring + dy
+	case dy == ring:
+		return ring, 4*ring - dx
+	case dx == -ring:
+		return ring, 6*ring - dy
+	default:
+		// The left half of the top edge, back around towards north
+		return ring, 8*ring + dx
+	}
+}
+
+// VisibleWalls returns the visible cells that are opaque on grid, in no particular order. Shadowcasting marks the
+// walls that cast its shadows as visible along with the floor, this picks them back out for drawing wall faces
+// differently
+func (v *View) VisibleWalls(grid GridMap) []Point {
+	cells := make([]Point, 0)
+	for k := range v.Visible {
+		if x, y := unpack(k); opaque(grid, x, y) {
+			cells = append(cells, Point{x, y})
+		}
+	}
+	return cells
+}
+
+// VisibleFloors returns the visible cells that are within grid and not opaque, in no particular order. Between them,
+// VisibleWalls and VisibleFloors hold every visible cell that is in bounds
+func (v *View) VisibleFloors(grid GridMap) []Point {
+	cells := make([]Point, 0)
+	for k := range v.Visible {
+		if x, y := unpack(k); grid.InBounds(x, y) && !grid.IsOpaque(x, y) {
+			cells = append(cells, Point{x, y})
+		}
+	}
+	return cells
+}
+
+// Shadows returns the tiles within radius r of px,py that are in bounds of grid but not visible, row by row from the
+// top, which is to say everywhere something could hide from the player. The radius is measured using the View's
+// Metric or DistanceFunc, so with the same arguments as the compute it holds exactly the tiles Compute couldn't see
+func (v *View) Shadows(grid GridMap, px, py, r int) []Point {
+	s := scan{px: px, py: py, metric: v.metric, distance: v.distance}
+	var tiles []Point
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			x, y := px+dx, py+dy
+			if s.within(dx, dy, r) && grid.InBounds(x, y) && !v.IsVisible(x, y) {
+				tiles = append(tiles, Point{x, y})
+			}
+		}
+	}
+	return tiles
+}
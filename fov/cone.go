@@ -0,0 +1,76 @@
+package fov
+
+import "math"
+
+// ComputeCone restricts the field of view to a vision cone. The usual shadowcast is performed, but only tiles
+// whose bearing from the player lies within halfAngle degrees of facing are kept. Bearings are measured in degrees
+// with 0 pointing east (+x) and increasing counter-clockwise as seen on screen, so 90 points north (-y) and 270
+// points south (+y). The cone may freely straddle 0/360, and the player's own tile is always visible
+func (v *View) ComputeCone(grid GridMap, px, py, radius int, facing, halfAngle float64) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.cone, s.facing, s.halfAngle = true, facing, halfAngle
+	v.run(&s)
+	return v
+}
+
+// Bearing returns the compass bearing in degrees, within [0, 360), from px,py to x,y, using the same compass as
+// ComputeCone. A point has a bearing of 0 from itself
+func Bearing(px, py, x, y int) float64 {
+	return bearing(x-px, y-py)
+}
+
+// BearingOf returns the Bearing of x,y from the origin of the most recent compute
+func (v *View) BearingOf(x, y int) float64 {
+	return Bearing(v.originX, v.originY, x, y)
+}
+
+// bearing returns the angle in degrees, within [0, 360), of the offset dx,dy using the compass described on
+// ComputeCone. A zero offset has a bearing of 0
+func bearing(dx, dy int) float64 {
+	deg := math.Atan2(float64(-dy), float64(dx)) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// ComputeConeLight is ComputeCone for a flashlight, recording how brightly each visible tile is lit like ComputeLight
+// does. The light fades with distance by LinearFalloff, and also fades out towards the sides of the cone, from full
+// strength straight ahead along facing to nothing at halfAngle degrees either side of it, so that the beam has a soft
+// edge rather than a hard one. LightAt reports the product of the two. The player's own tile is fully lit
+func (v *View) ComputeConeLight(grid GridMap, px, py, radius int, facing, halfAngle float64) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.cone, s.facing, s.halfAngle = true, facing, halfAngle
+	s.falloff, s.beam = LinearFalloff, true
+	v.run(&s)
+	return v
+}
+
+// inCone reports whether the offset dx,dy lies within halfAngle degrees either side of facing
+func inCone(dx, dy int, facing, halfAngle float64) bool {
+	return offAxis(dx, dy, facing) <= halfAngle
+}
+
+// offAxis returns how many degrees, from 0 to 180, the bearing of the offset dx,dy lies to either side of facing
+func offAxis(dx, dy int, facing float64) float64 {
+	// Fold the difference into (-180, 180] so that cones which wrap across 0 compare correctly
+	diff := math.Mod(bearing(dx, dy)-facing, 360)
+	if diff > 180 {
+		diff -= 360
+	} else if diff <= -180 {
+		diff += 360
+	}
+	return math.Abs(diff)
+}
+
+// spread returns the fraction of the beam's light that reaches the offset dx,dy, falling from 1 along facing to 0 at
+// the edge of the cone
+func (s *scan) spread(dx, dy int) float64 {
+	if dx == 0 && dy == 0 || s.halfAngle <= 0 {
+		return 1
+	}
+	if f := 1 - offAxis(dx, dy, s.facing)/s.halfAngle; f > 0 {
+		return f
+	}
+	return 0
+}
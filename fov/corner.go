@@ -0,0 +1,85 @@
+package fov
+
+import "math"
+
+// CornerRule decides what happens where two walls touch only at their corners, like the two #s of a diagonal wall,
+// leaving a gap between them that is infinitely thin
+type CornerRule int
+
+const (
+	// Permissive lets vision slip through the gap between diagonally touching walls, so that light seems to leak
+	// through a diagonal wall. This is the default
+	Permissive CornerRule = iota
+	// Strict seals the gap, a tile that lies diagonally behind two walls touching at their corners is neither visible
+	// nor can it be seen past. An unbroken diagonal wall then hides everything behind it, in every kind of compute
+	Strict
+)
+
+// WithCornerRule sets how every compute treats the gap between diagonally touching walls, see CornerRule
+func WithCornerRule(r CornerRule) Option {
+	return func(v *View) {
+		v.corners = r
+	}
+}
+
+// pinched reports whether, under the Strict rule, the tile offset dx,dy from the player is hidden behind the gap
+// between two diagonally touching walls. Those are its two neighbors one step closer to the player on each axis
+func (s *scan) pinched(dx, dy int) bool {
+	if s.corners != Strict {
+		return false
+	}
+	// Vision arrives from the eye, so a tile in line with the player on one axis is still reached from one side
+	// when ComputeOffset has moved the eye off center along that axis
+	sx, sy := sign(dx), sign(dy)
+	if dx == 0 {
+		sx = -signF(s.ox)
+	}
+	if dy == 0 {
+		sy = -signF(s.oy)
+	}
+	if sx == 0 || sy == 0 {
+		return false
+	}
+	return s.wallAt(dx-sx, dy) && s.wallAt(dx, dy-sy)
+}
+
+// CornerPeek decides how wide the shadow of a wall is in the octant scan, and with it how far the player can see around
+// corners. It has no effect on symmetric shadowcasting, which always uses the exact shape of each tile
+type CornerPeek int
+
+const (
+	// PeekWide casts shadows from the middle of each wall, as seen at the wall's distance, so that a player standing
+	// next to a corner can see a little way around it. This is the default
+	PeekWide CornerPeek = iota
+	// PeekTight casts shadows from the full extent of each wall, near corner to far corner, for a tighter feel with
+	// noticeably longer shadows. A pillar right next to the player hides everything behind it up to the diagonals, and
+	// the player has to actually step out from behind a corner to see past it
+	PeekTight
+)
+
+// WithCornerPeek sets how far around corners every compute sees, see CornerPeek
+func WithCornerPeek(p CornerPeek) Option {
+	return func(v *View) {
+		v.peek = p
+	}
+}
+
+// shadowStart returns the slope at which the shadow of the wall at height h and distance d of an octant begins
+func (s *scan) shadowStart(h, d float64) float64 {
+	if s.peek == PeekTight {
+		return (h - 0.5) / (d + 0.5)
+	}
+	return (h - 0.5) / d
+}
+
+// shadowEnd returns the slope at which the shadow of the wall at height h and distance d of an octant ends
+func (s *scan) shadowEnd(h, d float64) float64 {
+	if s.peek == PeekTight {
+		if d <= 0.5 {
+			// The near edge of the wall is level with the eye or behind it, so the shadow reaches all the way out
+			return math.Inf(1)
+		}
+		return (h + 0.5) / (d - 0.5)
+	}
+	return (h + 0.5) / d
+}
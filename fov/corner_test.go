@@ -0,0 +1,99 @@
+package fov
+
+import "testing"
+
+// diagonalWall returns a grid crossed by an unbroken diagonal wall of tiles that touch only at their corners, running
+// from 0,10 to 10,0
+func diagonalWall() *BoolGrid {
+	g := NewBoolGrid(16, 16)
+	for i := 0; i <= 10; i++ {
+		g.SetOpaque(i, 10-i, true)
+	}
+	return g
+}
+
+// behindWall counts the visible tiles on the far side of the wall from diagonalWall
+func behindWall(v *View) int {
+	n := 0
+	for k := range v.Visible {
+		if x, y := unpack(k); x+y > 10 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCornerRuleCheckerboardDiagonal(t *testing.T) {
+	g := diagonalWall()
+	computes := map[string]func(v *View) *View{
+		"Compute":          func(v *View) *View { return v.Compute(g, 3, 3, 12) },
+		"ComputeIterative": func(v *View) *View { return v.ComputeIterative(g, 3, 3, 12) },
+		"ComputeSymmetric": func(v *View) *View { return v.ComputeSymmetric(g, 3, 3, 12) },
+	}
+	for name, compute := range computes {
+		if v := compute(New(WithCornerRule(Strict))); behindWall(v) != 0 {
+			t.Errorf("%s: Strict sees %d tiles through the diagonal wall\n%s", name, behindWall(v),
+				v.Render(g, 3, 3, 12))
+		}
+		if v := compute(New(WithCornerRule(Permissive))); behindWall(v) == 0 {
+			t.Errorf("%s: Permissive should see through the gaps of the diagonal wall\n%s", name, v.Render(g, 3, 3, 12))
+		}
+	}
+}
+
+func TestCornerPeekAroundLShapedWall(t *testing.T) {
+	// A wall running left along y=10 and up along x=10 from the corner at 10,10, with the player standing below the
+	// horizontal arm, close to the corner, and the tiles up and to the right around the corner
+	g := NewBoolGrid(21, 21)
+	for i := 0; i <= 10; i++ {
+		g.SetOpaque(i, 10, true)
+		g.SetOpaque(10, i, true)
+	}
+	around := func(v *View) int {
+		n := 0
+		for k := range v.Visible {
+			if x, y := unpack(k); x > 10 && y < 10 {
+				n++
+			}
+		}
+		return n
+	}
+	for _, p := range []Point{{9, 11}, {8, 11}, {9, 12}} {
+		wide := New(WithCornerPeek(PeekWide)).Compute(g, p.X, p.Y, 12)
+		tight := New(WithCornerPeek(PeekTight)).Compute(g, p.X, p.Y, 12)
+		if around(tight) >= around(wide) {
+			t.Errorf("from %d,%d PeekTight sees %d tiles around the corner, PeekWide %d",
+				p.X, p.Y, around(tight), around(wide))
+		}
+		for k := range tight.Visible {
+			if _, ok := wide.Visible[k]; !ok {
+				x, y := unpack(k)
+				t.Errorf("from %d,%d PeekTight sees %d,%d but PeekWide doesn't", p.X, p.Y, x, y)
+			}
+		}
+	}
+}
+
+func TestStrictSealsDiagonalSeam(t *testing.T) {
+	// Two walls meeting only at a corner, with the tile at 11,11 right behind the seam between them as seen from the
+	// player on the diagonal
+	g := NewBoolGrid(16, 16)
+	g.SetOpaque(11, 10, true)
+	g.SetOpaque(10, 11, true)
+	computes := map[string]func(v *View, px, py int) *View{
+		"Compute":           func(v *View, px, py int) *View { return v.Compute(g, px, py, 6) },
+		"ComputeIterative":  func(v *View, px, py int) *View { return v.ComputeIterative(g, px, py, 6) },
+		"ComputeSymmetric":  func(v *View, px, py int) *View { return v.ComputeSymmetric(g, px, py, 6) },
+		"ComputePermissive": func(v *View, px, py int) *View { return v.ComputePermissive(g, px, py, 6, 2) },
+	}
+	for name, compute := range computes {
+		for _, p := range []Point{{10, 10}, {8, 8}} {
+			if v := compute(New(WithCornerRule(Strict)), p.X, p.Y); v.IsVisible(11, 11) {
+				t.Errorf("%s from %d,%d: Strict sees through the seam\n%s", name, p.X, p.Y, v.Render(g, p.X, p.Y, 6))
+			}
+			if v := compute(New(), p.X, p.Y); !v.IsVisible(11, 11) {
+				t.Errorf("%s from %d,%d: Permissive should see through the seam", name, p.X, p.Y)
+			}
+		}
+	}
+}
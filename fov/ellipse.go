@@ -0,0 +1,35 @@
+package fov
+
+import "math/bits"
+
+// ComputeEllipse limits the field of view to an ellipse rather than a circle, rx tiles across to either side of the
+// player and ry tiles up and down, for effects like a headlight beam or a letterboxed view. A tile offset dx,dy from
+// the player is within the ellipse when (dx/rx)^2 + (dy/ry)^2 is at most 1, so the edge is inclusive just as it is
+// for Compute, and equal radii give the same circle Compute does. A radius of 0 flattens the ellipse into a line
+// along the other axis
+func (v *View) ComputeEllipse(grid GridMap, px, py, rx, ry int) *View {
+	if rx < 0 {
+		rx = 0
+	}
+	if ry < 0 {
+		ry = 0
+	}
+	s := v.newScan(grid, px, py, max(rx, ry))
+	s.ellipse, s.rx, s.ry = true, rx, ry
+	v.run(&s)
+	return v
+}
+
+// inEllipse reports whether (dx/rx)^2 + (dy/ry)^2 is at most 1, comparing dx^2*ry^2 + dy^2*rx^2 against rx^2*ry^2
+// instead. Those are products of four coordinates, which can be too large even for 64 bits, so they are worked out
+// in 128
+func inEllipse(dx, dy, rx, ry int) bool {
+	dx2, dy2 := uint64(distSq(0, 0, dx, 0)), uint64(distSq(0, 0, dy, 0))
+	rx2, ry2 := uint64(distSq(0, 0, rx, 0)), uint64(distSq(0, 0, ry, 0))
+	hiX, loX := bits.Mul64(dx2, ry2)
+	hiY, loY := bits.Mul64(dy2, rx2)
+	lo, carry := bits.Add64(loX, loY, 0)
+	hi := hiX + hiY + carry
+	hiR, loR := bits.Mul64(rx2, ry2)
+	return hi < hiR || (hi == hiR && lo <= loR)
+}
@@ -0,0 +1,137 @@
+package fov
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"sort"
+)
+
+// viewJSON is the serialized form of a View, which deliberately doesn't depend on how cells are keyed internally.
+// Explored is only present when memory is enabled, even if nothing has been explored yet
+type viewJSON struct {
+	Visible  []Point     `json:"visible"`
+	Explored *[]seenJSON `json:"explored,omitempty"`
+	Turn     int         `json:"turn,omitempty"`
+}
+
+// seenJSON is an explored cell along with the turn it was last seen on
+type seenJSON struct {
+	Point
+	Turn int `json:"turn"`
+}
+
+// MarshalJSON encodes the visible cells as an array of {"x","y"} objects and, when memory is enabled, the explored
+// cells as an array of {"x","y","turn"} objects. Cells are written in sorted order so that the same View always
+// encodes the same way
+func (v *View) MarshalJSON() ([]byte, error) {
+	out := viewJSON{Visible: v.Visible.sorted(), Turn: v.turn}
+	if v.remember {
+		explored := make([]seenJSON, 0, len(v.explored))
+		for k, turn := range v.explored {
+			x, y := unpack(k)
+			explored = append(explored, seenJSON{Point{x, y}, turn})
+		}
+		sort.Slice(explored, func(i, j int) bool {
+			return less(explored[i].Point, explored[j].Point)
+		})
+		out.Explored = &explored
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores the visible and explored cells written by MarshalJSON, replacing whatever v held before.
+// Memory is enabled if the encoded View had it enabled, otherwise it is turned off and nothing is explored
+func (v *View) UnmarshalJSON(data []byte) error {
+	var in viewJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	v.Reset()
+	v.turn = in.Turn
+	for _, p := range in.Visible {
+		v.Visible[key(p.X, p.Y)] = struct{}{}
+	}
+	v.ClearMemory()
+	v.remember = false
+	if in.Explored != nil {
+		v.WithMemory()
+		for _, p := range *in.Explored {
+			v.explored[key(p.X, p.Y)] = p.Turn
+		}
+	}
+	return nil
+}
+
+// viewGob is the gob encoded form of a View, which keeps the cells packed as they are in memory to stay compact.
+// Seen holds the turn each of the Explored cells was last seen on
+type viewGob struct {
+	Visible  []int64
+	Explored []int64
+	Seen     []int
+	Memory   bool
+	Turn     int
+}
+
+// GobEncode encodes the visible cells, and the explored cells when memory is enabled, as sorted slices of packed
+// coordinates
+func (v *View) GobEncode() ([]byte, error) {
+	out := viewGob{Visible: v.Visible.keys(), Memory: v.remember, Turn: v.turn}
+	if v.remember {
+		out.Explored = make([]int64, 0, len(v.explored))
+		for k := range v.explored {
+			out.Explored = append(out.Explored, k)
+		}
+		sort.Slice(out.Explored, func(i, j int) bool {
+			return out.Explored[i] < out.Explored[j]
+		})
+		out.Seen = make([]int, len(out.Explored))
+		for i, k := range out.Explored {
+			out.Seen[i] = v.explored[k]
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores the visible and explored cells written by GobEncode, replacing whatever v held before. Memory
+// is enabled if the encoded View had it enabled, otherwise it is turned off and nothing is explored
+func (v *View) GobDecode(data []byte) error {
+	var in viewGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&in); err != nil {
+		return err
+	}
+	v.Reset()
+	v.turn = in.Turn
+	for _, k := range in.Visible {
+		v.Visible[k] = struct{}{}
+	}
+	v.ClearMemory()
+	v.remember = false
+	if in.Memory {
+		v.WithMemory()
+		for i, k := range in.Explored {
+			turn := 0
+			if i < len(in.Seen) {
+				turn = in.Seen[i]
+			}
+			v.explored[k] = turn
+		}
+	}
+	return nil
+}
+
+// keys returns the packed keys of the set in ascending order
+func (g CellSet) keys() []int64 {
+	keys := make([]int64, 0, len(g))
+	for k := range g {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
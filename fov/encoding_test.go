@@ -0,0 +1,96 @@
+package fov
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 30, 30, 5)
+	v := New(WithMemory())
+	v.SetTurn(3)
+	v.Compute(g, 5, 5, 6)
+	v.SetTurn(4)
+	v.Compute(g, 20, 20, 6)
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := New()
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	sameVisible(t, got, v, "after the round trip")
+	for k, turn := range v.explored {
+		x, y := unpack(k)
+		if seen, ok := got.LastSeen(x, y); !ok || seen != turn {
+			t.Fatalf("%d,%d was last seen on turn %d, got %d, %v", x, y, turn, seen, ok)
+		}
+	}
+	if len(got.explored) != len(v.explored) {
+		t.Errorf("got %d explored cells, want %d", len(got.explored), len(v.explored))
+	}
+}
+
+func TestJSONWithoutMemoryReplacesMemory(t *testing.T) {
+	g := NewBoolGrid(30, 30)
+	data, err := json.Marshal(New().Compute(g, 20, 20, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := New(WithMemory()).Compute(g, 5, 5, 6)
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsExplored(5, 5) || len(v.explored) != 0 {
+		t.Errorf("%d stale explored cells survived decoding a View without memory", len(v.explored))
+	}
+	if v.Compute(g, 5, 5, 6); v.IsExplored(5, 5) {
+		t.Error("memory should be off after decoding a View without memory")
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 30, 30, 5)
+	v := New(WithMemory())
+	v.SetTurn(7)
+	v.Compute(g, 5, 5, 6)
+	v.Compute(g, 20, 20, 6)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	got := New()
+	if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+		t.Fatal(err)
+	}
+	sameVisible(t, got, v, "after the round trip")
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			if got.IsVisible(x, y) != v.IsVisible(x, y) || got.IsExplored(x, y) != v.IsExplored(x, y) {
+				t.Fatalf("%d,%d differs after the round trip", x, y)
+			}
+		}
+	}
+}
+
+func TestGobWithoutMemoryReplacesMemory(t *testing.T) {
+	g := NewBoolGrid(30, 30)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(New().Compute(g, 20, 20, 3)); err != nil {
+		t.Fatal(err)
+	}
+	v := New(WithMemory()).Compute(g, 5, 5, 6)
+	if err := gob.NewDecoder(&buf).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsExplored(5, 5) || len(v.explored) != 0 {
+		t.Errorf("%d stale explored cells survived decoding a View without memory", len(v.explored))
+	}
+	if v.Compute(g, 5, 5, 6); v.IsExplored(5, 5) {
+		t.Error("memory should be off after decoding a View without memory")
+	}
+}
@@ -0,0 +1,25 @@
+package fov
+
+// ComputeFootprint computes the field of view of a creature that occupies several tiles, such as a 2x2 dragon, which
+// sees from every one of them. The result is the union of a Compute from each tile, so a pillar next to one of its
+// tiles doesn't leave it with a blind spot, but it is built up in a single visible set rather than merging several
+// Views. Every tile of the footprint is visible, and Origin reports the first of them. An empty footprint sees nothing
+func (v *View) ComputeFootprint(grid GridMap, tiles []Point, radius int) *View {
+	if len(tiles) == 0 {
+		v.Reset()
+		return v
+	}
+	for i, t := range tiles {
+		// A fresh scan for every tile, so that only the tiles standing in a wall are blind under WithBlindInWalls
+		s := v.newScan(grid, t.X, t.Y, radius)
+		if i == 0 {
+			v.begin(&s)
+		} else {
+			x, y := s.at(0, 0)
+			v.mark(&s, x, y, 0, 0)
+		}
+		v.sweep(&s)
+	}
+	v.memorize()
+	return v
+}
@@ -0,0 +1,44 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// union returns a View holding every cell visible in any of views
+func union(views ...*View) *View {
+	u := New()
+	for _, v := range views {
+		for k := range v.Visible {
+			u.Visible[k] = struct{}{}
+		}
+	}
+	return u
+}
+
+func TestComputeFootprintIsUnionOfComputes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	configs := [][]Option{nil, {WithBlindInWalls()}, {WithCornerRule(Strict)}}
+	for i := 0; i < 100; i++ {
+		g := randomGrid(r, 30, 30, 2+r.Intn(6))
+		tiles := []Point{{r.Intn(30), r.Intn(30)}, {r.Intn(30), r.Intn(30)}, {r.Intn(30), r.Intn(30)}}
+		radius := r.Intn(10)
+		opts := configs[i%len(configs)]
+		var views []*View
+		for _, p := range tiles {
+			views = append(views, New(opts...).Compute(g, p.X, p.Y, radius))
+		}
+		sameVisible(t, New(opts...).ComputeFootprint(g, tiles, radius), union(views...), "grid %d tiles %v radius %d",
+			i, tiles, radius)
+	}
+}
+
+func TestComputeFootprintBlindInWallsChecksEveryTile(t *testing.T) {
+	g := NewBoolGrid(20, 20)
+	g.SetOpaque(5, 5, true)
+	for _, tiles := range [][]Point{{{5, 5}, {6, 5}}, {{6, 5}, {5, 5}}} {
+		v := New(WithBlindInWalls()).ComputeFootprint(g, tiles, 3)
+		want := union(New(WithBlindInWalls()).Compute(g, 5, 5, 3), New(WithBlindInWalls()).Compute(g, 6, 5, 3))
+		sameVisible(t, v, want, "footprint %v", tiles)
+	}
+}
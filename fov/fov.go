@@ -1,105 +1,815 @@
 /*
 Package fov implements basic recursive shadowcasting for displaying a field of view on a 2D Grid
-The exact structure of the grid has been abstracted through an interface that merely provides 3 methods
-expected of any grid-based implementation
+The exact structure of the grid has been abstracted through an interface that merely provides the 2 methods
+expected of any grid-based implementation, along with an optional third for maps that wrap around
 */
 package fov
 
 import (
 	"math"
+	"sort"
 )
 
 // GridMap is meant to represent the basic functionality that is required to detect the opaqueness
-// and boundaries of a 2D grid
+// and boundaries of a 2D grid. IsOpaque is only ever called for a tile once InBounds has reported it to be in bounds,
+// so it may index straight into the map without checking, and tiles outside of the grid never block vision unless a
+// View is created WithBorderOpaque. The same goes for the methods of the optional interfaces, such as Transmittance
+// and Height. For an IndexedGrid both are called with the coordinates Index returned
 type GridMap interface {
 	InBounds(x, y int) bool
 	IsOpaque(x, y int) bool
 }
 
-//point to hold a x, y position
-type point struct {
-	x, y int
+// IndexedGrid is a GridMap that normalizes coordinates before they are used, such as a toroidal world map that wraps
+// around at its edges. When the grid passed to a compute implements it, every tile coordinate is passed through Index
+// before InBounds, IsOpaque or the visible set ever see it, so vision wraps around with the map. Distances are still
+// measured from the player before wrapping. A grid that doesn't wrap can simply return its arguments
+type IndexedGrid interface {
+	GridMap
+	Index(x, y int) (int, int)
 }
 
-// gridSet is an efficient and idiomatic way to implement sets in go, as an empty struct takes up no space
-// and nothing more than a set of keys is needed to store the range of visible cells
-type gridSet map[point]struct{}
+// CellSet is an efficient and idiomatic way to implement sets in go, as an empty struct takes up no space
+// and nothing more than a set of keys is needed to store the range of visible cells. Each cell is keyed by
+// its coordinates packed into a single int64 (see key), which hashes much faster than a struct or string key.
+// The packing is an implementation detail, so rather than using the map directly, use the methods below, which
+// work in terms of plain coordinates. A CellSet has to be made before anything can be added to it
+type CellSet map[int64]struct{}
+
+// Add puts the cell at x,y into the set
+func (g CellSet) Add(x, y int) {
+	g[key(x, y)] = struct{}{}
+}
+
+// Remove takes the cell at x,y out of the set, if it is there
+func (g CellSet) Remove(x, y int) {
+	delete(g, key(x, y))
+}
+
+// Has reports whether the cell at x,y is in the set
+func (g CellSet) Has(x, y int) bool {
+	_, ok := g[key(x, y)]
+	return ok
+}
+
+// Len returns the number of cells in the set
+func (g CellSet) Len() int {
+	return len(g)
+}
+
+// Slice returns every cell in the set, in no particular order
+func (g CellSet) Slice() []Point {
+	return g.points()
+}
+
+// key packs an x,y pair into a single int64 with x in the high 32 bits and y in the low 32 bits. The uint32
+// conversion keeps a negative y from sign-extending over the bits that hold x. Coordinates therefore have to fit into
+// an int32, from -2147483648 to 2147483647, anything beyond that is truncated and collides with other cells
+func key(x, y int) int64 {
+	return int64(x)<<32 | int64(uint32(y))
+}
+
+// clear empties the set in place, keeping its storage around for reuse
+func (g CellSet) clear() {
+	// The compiler recognizes this loop and turns it into a single map clear
+	for k := range g {
+		delete(g, k)
+	}
+}
+
+// copy returns an independent copy of the set, or nil if the set is nil
+func (g CellSet) copy() CellSet {
+	if g == nil {
+		return nil
+	}
+	c := make(CellSet, len(g))
+	for k := range g {
+		c[k] = struct{}{}
+	}
+	return c
+}
+
+// points returns every cell in the set, in no particular order
+func (g CellSet) points() []Point {
+	cells := make([]Point, 0, len(g))
+	for k := range g {
+		x, y := unpack(k)
+		cells = append(cells, Point{x, y})
+	}
+	return cells
+}
+
+// sorted returns every cell in the set, sorted by Y and then by X
+func (g CellSet) sorted() []Point {
+	cells := g.points()
+	sort.Slice(cells, func(i, j int) bool {
+		return less(cells[i], cells[j])
+	})
+	return cells
+}
+
+// less is the order of sorted, by Y and then by X. It compares coordinates rather than packed keys, so the order
+// doesn't depend on how cells are keyed
+func less(a, b Point) bool {
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.X < b.X
+}
+
+// unpack reverses key, recovering the x,y pair from a packed cell
+func unpack(k int64) (int, int) {
+	return int(k >> 32), int(int32(k))
+}
+
+// Point is a single x,y cell on the grid
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
 
 // View is the item which stores the visible set of cells any time it is called. This should be called any time
 // a player's position is updated
+//
+// The zero value of a View behaves just like one returned by New without options, though New is the usual way to
+// get one. A View is not safe for concurrent use. Every compute rewrites the visible set in place, so reading a View
+// on one goroutine while another computes it is a data race. To render from a different goroutine, hand it a
+// Snapshot after each compute instead
 type View struct {
-	Visible gridSet
+	Visible CellSet
+	// Bright and Dim split the visible set into brightly and dimly lit cells when computed with ComputeTiered
+	Bright, Dim CellSet
+	// OnReveal, when set, is called by every compute with the coordinates of each tile as it is added to the visible
+	// set, exactly once per tile even where the scans overlap. It is called in the middle of the compute, so it must
+	// not use the View itself
+	OnReveal func(x, y int)
+
+	// light holds the brightness of each visible tile when computed with ComputeLight
+	light map[int64]float64
+	// distances holds the distance of each visible tile from the player, but only once enabled by WithDistances
+	distances map[int64]int
+	// seenBy holds the IDs of the sources that see each visible tile when computed with ComputeTeam
+	seenBy map[int64][]int
+	// explored accumulates every cell that has ever been visible, along with the turn it was last seen on, but only
+	// once memory is enabled with WithMemory. It is read through IsExplored and LastSeen
+	explored memory
+	// remember is set by WithMemory and causes every compute to add its result to explored, as seen on turn
+	remember bool
+	turn     int
+	// metric and symmetric are the defaults for every compute, set by the WithMetric and WithSymmetric options
+	metric    Metric
+	symmetric bool
+	// distance, when set by WithDistanceFunc, measures the radius in place of metric
+	distance DistanceFunc
+	// corners and peek are the CornerRule and CornerPeek of every compute, set by WithCornerRule and WithCornerPeek
+	corners CornerRule
+	peek    CornerPeek
+	// blindInWalls is set by WithBlindInWalls and stops a player inside an opaque tile from seeing out of it
+	blindInWalls bool
+	// borderOpaque is set by WithBorderOpaque and makes every tile outside the grid block vision
+	borderOpaque bool
+	// ringOrigin is set by WithRingOrigin and keeps the player's own tile visible in ComputeRing
+	ringOrigin bool
+	// stack holds the rows still waiting to be scanned by ComputeIterative, kept around between computes
+	stack []row
+	// parts holds the per-octant results of ComputeParallel, kept around between computes
+	parts []*View
+	// mask holds the offsets given to ComputeMasked, kept around between computes
+	mask CellSet
+	// spare holds the visible set from before the most recent RecomputeDiff, to be reused by the next one
+	spare CellSet
+	// cache holds the results of ComputeCached once enabled by WithCache
+	cache *cache
+	// originX, originY and radius describe the most recent compute, see Origin
+	originX, originY, radius int
 }
 
-// New returns a new instance of an fov calculator
-func New() *View {
-	return &View{}
+// New returns a new instance of an fov calculator, configured by any options provided. With no options it measures
+// distance as a straight line, uses recursive shadowcasting and doesn't remember explored tiles. Nothing is visible
+// until the first compute, but the View can be queried straight away
+func New(opts ...Option) *View {
+	v := &View{Visible: make(CellSet)}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // Compute takes a GridMap implementation along with the x and y coordinates representing a player's current
-// position and will internally update the visibile set of tiles within the provided radius `r`
-func (v *View) Compute(grid GridMap, px, py, radius int) {
-	v.Visible = make(map[point]struct{})
-	v.Visible[point{px, py}] = struct{}{}
-	for i := 1; i <= 8; i++ {
-		v.fov(grid, px, py, 1, 0, 1, i, radius)
+// position and will internally update the visibile set of tiles within the provided radius `r`. The radius is
+// inclusive, so a tile exactly `r` away from the player can be visible. A radius of 0 or less leaves only the player's
+// own tile visible. Positions may be anywhere in the range of an int32, the player being millions of tiles from the
+// origin of the map makes no difference to the result. The field of view has all the symmetry the map has around the
+// player, so walls that mirror each other across an axis or a diagonal through the player are seen alike. Each octant
+// is scanned by the same arithmetic as seen in a mirror, and a tile on an axis or a diagonal, shared by two octants,
+// is visible when either of them sees it, which leaves nothing to the order the octants are scanned in. Like every
+// other compute it returns v, so that a View can be set up in one go, e.g. fov.New().Compute(grid, x, y, 6)
+func (v *View) Compute(grid GridMap, px, py, radius int) *View {
+	s := v.newScan(grid, px, py, radius)
+	v.run(&s)
+	return v
+}
+
+// ComputeMetric is identical to Compute, except the radius is measured using the provided Metric rather than the
+// Metric or DistanceFunc the View was configured with
+func (v *View) ComputeMetric(grid GridMap, px, py, radius int, m Metric) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.metric, s.distance = m, nil
+	v.run(&s)
+	return v
+}
+
+// ComputeF is identical to Compute, except the radius doesn't have to be a whole number, for effects like a
+// flickering torch whose radius is animated smoothly. Tiles at the edge come into view one by one as the radius grows,
+// rather than a whole ring at a time. Only straight line distance has tiles between the whole numbers, under the
+// other metrics ComputeF sees exactly what Compute would with the radius rounded down
+func (v *View) ComputeF(grid GridMap, px, py int, radius float64) *View {
+	s := v.newScan(grid, px, py, int(math.Floor(radius)))
+	s.fractional, s.radiusF = true, radius
+	v.run(&s)
+	return v
+}
+
+// ComputeInto performs a Compute into dst, reusing whatever storage dst already holds, and returns it. If dst is
+// nil a new View is allocated instead. Once a View has been computed at a given radius, computing it again at the
+// same radius does not allocate, which makes ComputeInto a natural fit for Views kept in a sync.Pool when many
+// light sources are recomputed every frame
+func ComputeInto(dst *View, grid GridMap, px, py, radius int) *View {
+	if dst == nil {
+		dst = New()
+	}
+	dst.Compute(grid, px, py, radius)
+	return dst
+}
+
+// Clone returns a deep copy of v, including the visible set, any explored tiles and lighting, along with the options
+// v was created with. Nothing done to the clone afterwards, including computing it again, affects v or vice versa.
+// The one thing not copied is the cache of ComputeCached, the clone starts out with an empty cache of the same size
+func (v *View) Clone() *View {
+	c := &View{
+		Visible:  v.Visible.copy(),
+		Bright:   v.Bright.copy(),
+		Dim:      v.Dim.copy(),
+		OnReveal: v.OnReveal,
+
+		explored:     v.explored.copy(),
+		remember:     v.remember,
+		turn:         v.turn,
+		metric:       v.metric,
+		distance:     v.distance,
+		symmetric:    v.symmetric,
+		corners:      v.corners,
+		peek:         v.peek,
+		blindInWalls: v.blindInWalls,
+		borderOpaque: v.borderOpaque,
+		ringOrigin:   v.ringOrigin,
+		originX:      v.originX,
+		originY:      v.originY,
+		radius:       v.radius,
+	}
+	if v.light != nil {
+		c.light = make(map[int64]float64, len(v.light))
+		for k, b := range v.light {
+			c.light[k] = b
+		}
 	}
+	if v.seenBy != nil {
+		c.seenBy = make(map[int64][]int, len(v.seenBy))
+		for k, ids := range v.seenBy {
+			c.seenBy[k] = append([]int(nil), ids...)
+		}
+	}
+	if v.cache != nil {
+		c.cache = newCache(v.cache.size)
+	}
+	if v.distances != nil {
+		c.distances = make(map[int64]int, len(v.distances))
+		for k, d := range v.distances {
+			c.distances[k] = d
+		}
+	}
+	return c
+}
+
+// Snapshot returns a copy of the result of the most recent compute that is safe to hand to another goroutine, since
+// nothing v does afterwards touches it. Any number of goroutines may read the snapshot at once, as long as none of
+// them computes it or otherwise changes it
+func (v *View) Snapshot() *View {
+	return v.Clone()
+}
+
+// Reset empties the visible set while holding on to its storage, so that recomputing every frame doesn't have to
+// allocate a brand new set each time. Compute calls this itself, it only needs to be called directly in order to
+// hide everything without computing again
+func (v *View) Reset() {
+	for k := range v.light {
+		delete(v.light, k)
+	}
+	for k := range v.distances {
+		delete(v.distances, k)
+	}
+	for k := range v.seenBy {
+		delete(v.seenBy, k)
+	}
+	v.Bright.clear()
+	v.Dim.clear()
+	if v.Visible == nil {
+		v.Visible = make(CellSet)
+		return
+	}
+	v.Visible.clear()
+}
+
+// scan holds the parameters of a single compute. Each variation of Compute describes the area it is interested in
+// through these fields and then hands the scan off to the very same shadowcasting routine. They are plain values
+// rather than closures so that a scan never escapes to the heap, keeping repeated computes allocation free
+type scan struct {
+	grid GridMap
+	// index and transparent are the grid again when it also implements IndexedGrid or TransparentGrid respectively,
+	// and nil otherwise
+	index       IndexedGrid
+	transparent TransparentGrid
+	px, py      int
+	// depth is the furthest distance from the player, along the major axis of an octant, that will be scanned
+	depth int
+	// metric, or distance when set, and radius decide which of the scanned tiles are close enough to be marked
+	// visible, while tiles closer than minRadius are left out. Leaving out the player's own tile is up to hideOrigin
+	metric     Metric
+	distance   DistanceFunc
+	radius     int
+	minRadius  int
+	hideOrigin bool
+	// fractional, when set by ComputeF, measures the radius against radiusF rather than radius
+	fractional bool
+	radiusF    float64
+	// team, when set by ComputeTeam, records source as one of the sources that see every visible tile
+	team   bool
+	source int
+	// square, when set by ComputeSquare, skips the radius check altogether
+	square bool
+	// ellipse, when set by ComputeEllipse, replaces the radius with an ellipse whose radii are rx and ry
+	ellipse bool
+	rx, ry  int
+	// cone, when set, additionally limits the visible tiles to those within halfAngle degrees of facing
+	cone              bool
+	facing, halfAngle float64
+	// falloff, when set, records a brightness for every visible tile, further dimmed towards the sides of the cone
+	// when beam is set
+	falloff Falloff
+	beam    bool
+	// tiered, when set, sorts every visible tile into Bright or Dim depending on whether it is within brightRadius
+	tiered       bool
+	brightRadius int
+	// symmetric selects symmetric shadowcasting in place of the recursive octant scan
+	symmetric bool
+	// corners decides whether vision can slip between two diagonally touching walls, and peek how far around corners
+	// the octant scan sees
+	corners CornerRule
+	peek    CornerPeek
+	// borderOpaque treats every tile outside the grid as a wall
+	borderOpaque bool
+	// iterative scans octants using View.stack rather than recursion
+	iterative bool
+	// octants, when not zero, limits the octant scan to the octants whose bits are set, see ComputeOctants
+	octants uint16
+	// perOctant replaces the radius with radii[i-1] while scanning octant i, see ComputeRadii
+	perOctant bool
+	radii     [8]int
+	// rect, when set, keeps every tile outside of minX,minY to maxX,maxY out of the result, see ComputeInRect
+	rect                   bool
+	minX, minY, maxX, maxY int
+	// height, when set by ComputeHeight, hides tiles that are behind something taller than the line of sight to them
+	// from an eye at the given elevation
+	height HeightGrid
+	eye    int
+	// ox and oy move the eye away from the center of the player's tile, see ComputeOffset
+	ox, oy float64
+	// mask, when set, holds the only offsets from the player that may be marked visible, see ComputeMasked
+	mask CellSet
+}
+
+// inRange reports whether the tile offset dx,dy from the player may be marked visible
+func (s *scan) inRange(dx, dy int) bool {
+	switch {
+	case s.mask != nil:
+		if !s.mask.Has(dx, dy) {
+			return false
+		}
+	case s.square:
+		// The depth of the scan already keeps every tile within the square
+	case s.ellipse:
+		if !inEllipse(dx, dy, s.rx, s.ry) {
+			return false
+		}
+	case s.fractional:
+		if s.dist(dx, dy) > s.radiusF {
+			return false
+		}
+	case !s.within(dx, dy, s.radius):
+		return false
+	}
+	if s.closer(dx, dy, s.minRadius) {
+		return false
+	}
+	if s.cone && !inCone(dx, dy, s.facing, s.halfAngle) {
+		return false
+	}
+	return true
+}
+
+// newScan prepares a scan of the given radius around px,py using the View's configured defaults, for the caller to
+// adjust before running it
+func (v *View) newScan(grid GridMap, px, py, radius int) scan {
+	index, _ := grid.(IndexedGrid)
+	transparent, _ := grid.(TransparentGrid)
+	s := scan{grid: grid, index: index, transparent: transparent, px: px, py: py, depth: radius, metric: v.metric,
+		distance: v.distance, radius: radius, symmetric: v.symmetric, corners: v.corners,
+		peek: v.peek, borderOpaque: v.borderOpaque}
+	if v.blindInWalls {
+		if x, y := s.at(0, 0); opaque(grid, x, y) {
+			s.depth = 0
+		}
+	}
+	return s
+}
+
+// at returns the map coordinates of the tile offset dx,dy from the player, wrapped by the grid if it is indexed
+func (s *scan) at(dx, dy int) (int, int) {
+	if s.index != nil {
+		return s.index.Index(s.px+dx, s.py+dy)
+	}
+	return s.px + dx, s.py + dy
+}
+
+// wall reports whether the tile at x,y is a wall as far as the scan is concerned, which is to say opaque, or outside
+// the grid when the border is opaque
+func (s *scan) wall(x, y int) bool {
+	if !s.grid.InBounds(x, y) {
+		return s.borderOpaque
+	}
+	return s.grid.IsOpaque(x, y)
+}
+
+// run clears out the previous result and performs all eight octant scans described by s, or all four quadrant scans
+// for symmetric shadowcasting
+func (v *View) run(s *scan) {
+	v.begin(s)
+	v.sweep(s)
+	v.memorize()
+}
+
+// sweep performs the scans described by s, adding to whatever is already visible
+func (v *View) sweep(s *scan) {
+	// With no radius to speak of there is nothing to scan, the player can see at most their own tile
+	if s.depth <= 0 {
+		return
+	}
+	if s.symmetric {
+		for q := 0; q < 4; q++ {
+			v.quadrant(s, q, 1, fraction{-1, 1}, fraction{1, 1})
+		}
+	} else {
+		depth, radius := s.depth, s.radius
+		for i := 1; i <= 8; i++ {
+			d := depth
+			if s.perOctant {
+				s.radius, d = s.radii[i-1], s.radii[i-1]
+			}
+			if s.depth = s.reach(d, i); s.depth <= 0 {
+				continue
+			}
+			v.fov(s, s.firstRow(i), 0, 1, i)
+			// When scanning iteratively, the call above has only scanned the first row and saved the rows beyond it
+			for len(v.stack) > 0 {
+				r := v.stack[len(v.stack)-1]
+				v.stack = v.stack[:len(v.stack)-1]
+				v.fov(s, r.dist, r.lowSlope, r.highSlope, r.oct)
+			}
+		}
+		s.depth, s.radius = depth, radius
+	}
+}
+
+// begin clears out the previous result, prepares whatever s is going to record and marks the player's own tile
+func (v *View) begin(s *scan) {
+	v.Reset()
+	v.originX, v.originY, v.radius = s.px, s.py, s.radius
+	if s.falloff != nil && v.light == nil {
+		v.light = make(map[int64]float64)
+	}
+	if s.tiered && v.Bright == nil {
+		v.Bright, v.Dim = make(CellSet), make(CellSet)
+	}
+	if !s.hideOrigin {
+		x, y := s.at(0, 0)
+		v.mark(s, x, y, 0, 0)
+	}
+}
+
+// Origin returns the player position and radius used by the most recent compute
+func (v *View) Origin() (x, y, radius int) {
+	return v.originX, v.originY, v.radius
+}
+
+// row is a single row of an octant scan that has yet to be performed, see ComputeIterative
+type row struct {
+	dist                int
+	lowSlope, highSlope float64
+	oct                 int
+}
+
+// descend continues the scan of an octant into the row at dist, either straight away through recursion or, when
+// scanning iteratively, by saving the row on the stack for run to get to later
+func (v *View) descend(s *scan, dist int, lowSlope, highSlope float64, oct int) {
+	if s.iterative {
+		v.stack = append(v.stack, row{dist, lowSlope, highSlope, oct})
+		return
+	}
+	v.fov(s, dist, lowSlope, highSlope, oct)
+}
+
+// mark adds the tile at x,y, which is offset dx,dy from the player, to the visible set along with anything else the
+// scan has asked to be recorded. Neighboring octants overlap along the axes and diagonals, so the same tile is often
+// marked more than once in a compute. Only the first time counts, nothing is ever recorded for a tile twice
+func (v *View) mark(s *scan, x, y, dx, dy int) {
+	if s.rect && (x < s.minX || y < s.minY || x > s.maxX || y > s.maxY) {
+		return
+	}
+	k := key(x, y)
+	if s.team {
+		// Sources are scanned one after the other, so a source that has already seen the tile is the last one seen
+		if ids := v.seenBy[k]; len(ids) == 0 || ids[len(ids)-1] != s.source {
+			v.seenBy[k] = append(ids, s.source)
+		}
+	}
+	if !v.insert(k) {
+		return
+	}
+	if v.distances != nil {
+		v.distances[k] = s.steps(dx, dy)
+	}
+	if s.falloff != nil {
+		b := brightness(s.falloff, s.dist(dx, dy), float64(s.radius))
+		if s.beam {
+			b *= s.spread(dx, dy)
+		}
+		v.light[k] = b
+	}
+	if s.tiered {
+		if s.within(dx, dy, s.brightRadius) {
+			v.Bright[k] = struct{}{}
+		} else {
+			v.Dim[k] = struct{}{}
+		}
+	}
+}
+
+// insert adds the packed cell k to the visible set, reporting whether it wasn't already there. OnReveal is only
+// called for cells that are new
+func (v *View) insert(k int64) bool {
+	if _, ok := v.Visible[k]; ok {
+		return false
+	}
+	v.Visible[k] = struct{}{}
+	if v.OnReveal != nil {
+		v.OnReveal(unpack(k))
+	}
+	return true
 }
 
 // fov does the actual work of detecting the visible tiles based on the recursive shadowcasting algorithm
 // annotations provided inline below for (hopefully) easier learning
-func (v *View) fov(grid GridMap, px, py, dist int, lowSlope, highSlope float64, oct, rad int) {
+func (v *View) fov(s *scan, dist int, lowSlope, highSlope float64, oct int) {
 	// If the current distance is greater than the radius provided, then this is the end of the iteration
-	if dist > rad {
+	if dist > s.depth {
 		return
 	}
 
+	// Slopes are measured from the eye, which is the center of the player's tile unless ComputeOffset moved it
+	// by od along the distance axis and oh along the height axis of this octant
+	d, od, oh := float64(dist), 0.0, 0.0
+	if s.ox != 0 || s.oy != 0 {
+		od, oh = s.eyeIn(oct)
+	}
+
 	// Convert our slope into integers that will represent the "height" from the player position
 	// "height" will alternately apply to x OR y coordinates as we move around the octants
-	low := math.Floor(lowSlope*float64(dist) + 0.5)
-	high := math.Floor(highSlope*float64(dist) + 0.5)
+	low := math.Floor(lowSlope*(d-od) + oh + 0.5)
+	high := math.Floor(highSlope*(d-od) + oh + 0.5)
+	if od != 0 || oh != 0 {
+		// Seen from the center of the player's tile a slope never ends exactly on the edge between two tiles, but from
+		// anywhere else it easily can, and the tile beyond an edge that is merely touched must be left out, just as
+		// low leaves out the tile below it
+		high = math.Ceil(highSlope*(d-od) + oh - 0.5)
+	}
 
 	// inGap refers to whether we are currently scanning non-blocked tiles consecutively
 	// inGap = true means that the previous tile examined was empty
 	inGap := false
 
 	for height := low; height <= high; height++ {
-		// Given the player coords and a distance, height and octant, determine which tile is being visited
-		mapx, mapy := distHeightXY(px, py, dist, int(height), oct)
-		if grid.InBounds(mapx, mapy) && distTo(px, py, mapx, mapy) < rad {
+		// Given a distance, height and octant, determine which tile is being visited relative to the player
+		dx, dy := distHeightXY(0, 0, dist, int(height), oct)
+		mapx, mapy := s.at(dx, dy)
+		if s.grid.InBounds(mapx, mapy) && s.inRange(dx, dy) && s.clear(dx, dy) {
 			// As long as a tile is within the bounds of the map, if we visit it at all, it is considered visible
 			// That's the efficiency of shadowcasting, you just dont visit tiles that aren't visible
-			v.Visible[point{mapx, mapy}] = struct{}{}
+			v.mark(s, mapx, mapy, dx, dy)
 		}
 
-		if grid.InBounds(mapx, mapy) && grid.IsOpaque(mapx, mapy) {
+		// The player's own tile never blocks, it only comes up at all when the eye has been moved behind its row
+		if (dist > 0 || height != 0) && s.blocks(mapx, mapy, dx, dy) {
 			if inGap {
 				// An opaque tile was discovered, so begin a recursive call
-				v.fov(grid, px, py, dist+1, lowSlope, (height-0.5)/float64(dist), oct, rad)
+				v.descend(s, dist+1, lowSlope, s.shadowStart(height-oh, d-od), oct)
 			}
 			// Any time a recursive call is made, adjust the minimum slope for all future calls within this octant
-			lowSlope = (height + 0.5) / float64(dist)
+			lowSlope = s.shadowEnd(height-oh, d-od)
 			inGap = false
 		} else {
 			inGap = true
 			// We've reached the end of the scan and, since the last tile in the scan was empty, begin
 			// another on the next depth up
 			if height == high {
-				v.fov(grid, px, py, dist+1, lowSlope, highSlope, oct, rad)
+				v.descend(s, dist+1, lowSlope, highSlope, oct)
 			}
 		}
 	}
 }
 
-// IsVisible takes in a set of x,y coordinates and will consult the visible set (as a gridSet) to determine
+// IsVisible takes in a set of x,y coordinates and will consult the visible set (as a CellSet) to determine
 // whether that tile is visible.
 func (v *View) IsVisible(x, y int) bool {
-	if _, ok := v.Visible[point{x, y}]; ok {
-		return true
+	return v.Visible.Has(x, y)
+}
+
+// IsVisibleIndex is IsVisible for maps stored in a flat slice, row by row, where the tile at x,y lives at index
+// y*width + x. A negative index, or a width of 0 or less, is never visible
+func (v *View) IsVisibleIndex(idx, width int) bool {
+	if idx < 0 || width <= 0 {
+		return false
+	}
+	return v.IsVisible(idx%width, idx/width)
+}
+
+// Contains is the Point flavored equivalent of IsVisible, for callers that already work in terms of Points
+func (v *View) Contains(p Point) bool {
+	return v.IsVisible(p.X, p.Y)
+}
+
+// VisibleAmong returns the points which are visible, in the order they were given
+func (v *View) VisibleAmong(points []Point) []Point {
+	var visible []Point
+	for _, p := range points {
+		if v.Contains(p) {
+			visible = append(visible, p)
+		}
+	}
+	return visible
+}
+
+// AllVisible reports whether every one of the points is visible, which is trivially true when there are none
+func (v *View) AllVisible(points []Point) bool {
+	for _, p := range points {
+		if !v.Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAll is AllVisible for points given one by one, e.g. v.ContainsAll(door, lever)
+func (v *View) ContainsAll(points ...Point) bool {
+	return v.AllVisible(points)
+}
+
+// Count returns the number of visible cells
+func (v *View) Count() int {
+	return len(v.Visible)
+}
+
+// Bounds returns the smallest rectangle, inclusive on all sides, that contains every visible cell. ok is false when
+// nothing is visible, in which case the coordinates are meaningless
+func (v *View) Bounds() (minX, minY, maxX, maxY int, ok bool) {
+	for k := range v.Visible {
+		x, y := unpack(k)
+		if !ok {
+			minX, minY, maxX, maxY, ok = x, y, x, y, true
+			continue
+		}
+		if x < minX {
+			minX = x
+		} else if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		} else if y > maxY {
+			maxY = y
+		}
+	}
+	return minX, minY, maxX, maxY, ok
+}
+
+// VisibleCells returns every cell in the visible set. The order of the cells is not guaranteed, use
+// VisibleCellsSorted when a stable order is needed
+func (v *View) VisibleCells() []Point {
+	return v.Visible.points()
+}
+
+// CellsInRect returns the visible cells within minX,minY to maxX,maxY, inclusive on all sides, in no particular
+// order. A rectangle that doesn't overlap the visible region, or whose minimum exceeds its maximum, has no cells
+func (v *View) CellsInRect(minX, minY, maxX, maxY int) []Point {
+	cells := make([]Point, 0)
+	// No cell lies outside the range of an int32, see key, so the rectangle can be clamped to it. That also keeps the
+	// sizes below from overflowing and the loops from wrapping around
+	x0, y0, x1, y1 := clamp32(minX), clamp32(minY), clamp32(maxX), clamp32(maxY)
+	if x0 > x1 || y0 > y1 {
+		return cells
+	}
+	// Whichever of the rectangle and the visible set is smaller is the one worth walking
+	if w, h := x1-x0+1, y1-y0+1; w <= int64(len(v.Visible))/h {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				if v.IsVisible(int(x), int(y)) {
+					cells = append(cells, Point{int(x), int(y)})
+				}
+			}
+		}
+		return cells
+	}
+	for k := range v.Visible {
+		if x, y := unpack(k); x >= minX && y >= minY && x <= maxX && y <= maxY {
+			cells = append(cells, Point{x, y})
+		}
+	}
+	return cells
+}
+
+// clamp32 limits n to the range of an int32
+func clamp32(n int) int64 {
+	switch {
+	case int64(n) < math.MinInt32:
+		return math.MinInt32
+	case int64(n) > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return int64(n)
+}
+
+// ForEach calls fn with the coordinates of each visible cell, in no particular order, stopping early as soon as fn
+// returns false. Unlike VisibleCells nothing is allocated
+func (v *View) ForEach(fn func(x, y int) bool) {
+	for k := range v.Visible {
+		if !fn(unpack(k)) {
+			return
+		}
+	}
+}
+
+// FillMap empties m and then sets it to true for each visible cell, for renderers that keep a map of their own
+// around between frames. Reusing the same map saves allocating a new one on every compute. The method isn't called
+// WriteTo because that name is reserved for io.WriterTo
+func (v *View) FillMap(m map[Point]bool) {
+	// The compiler recognizes this loop and turns it into a single map clear, as the clear builtin would
+	for p := range m {
+		delete(m, p)
+	}
+	for k := range v.Visible {
+		x, y := unpack(k)
+		m[Point{x, y}] = true
+	}
+}
+
+// AnyVisible reports whether pred is true for any visible cell, e.g. whether a tile holding an enemy is in view. It
+// stops at the first cell pred is true for, and cells are tried in no particular order
+func (v *View) AnyVisible(pred func(x, y int) bool) bool {
+	for k := range v.Visible {
+		if pred(unpack(k)) {
+			return true
+		}
 	}
 	return false
 }
 
+// FilterVisible returns the visible cells that pred is true for, in no particular order
+func (v *View) FilterVisible(pred func(x, y int) bool) []Point {
+	var cells []Point
+	for k := range v.Visible {
+		if x, y := unpack(k); pred(x, y) {
+			cells = append(cells, Point{x, y})
+		}
+	}
+	return cells
+}
+
+// VisibleCellsSorted returns the same cells as VisibleCells, sorted by Y and then by X, which is the order they would
+// be read in from the top left of the map, row by row. The order only depends on which cells are visible, so it is
+// the same from one run to the next and is well suited to comparing results in tests
+func (v *View) VisibleCellsSorted() []Point {
+	return v.Visible.sorted()
+}
+
 // distHeightXY performs some bitwise and operations to handle the transposition of the depth and height values
 // since the concept of "depth" and "height" is relative to whichever octant is currently being scanned
 func distHeightXY(px, py, d, h, oct int) (int, int) {
@@ -115,10 +825,12 @@ func distHeightXY(px, py, d, h, oct int) (int, int) {
 	return px + d, py + h
 }
 
-// distTo is simply a helper function to determine the distance between two points, for checking visibility of a tile
-// within a provided radius
-func distTo(x1, y1, x2, y2 int) int {
-	vx := math.Pow(float64(x1-x2), 2)
-	vy := math.Pow(float64(y1-y2), 2)
-	return int(math.Sqrt(vx + vy))
-}
\ No newline at end of file
+// distSq is a helper function to determine the squared distance between two points. Radius checks compare this
+// against the squared radius so that no square root (and no rounding of its result) is ever involved, which keeps the
+// boundary of the field of view identical in every octant. Radius checks only ever pass it offsets from the player,
+// never map coordinates, and it works in 64 bits even where int is 32, so neither far off players nor large radii
+// can overflow it
+func distSq(x1, y1, x2, y2 int) int64 {
+	dx, dy := int64(x1)-int64(x2), int64(y1)-int64(y2)
+	return dx*dx + dy*dy
+}
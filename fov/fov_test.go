@@ -0,0 +1,340 @@
+package fov
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomGrid returns a w by h BoolGrid with roughly one tile in every density turned into a wall
+func randomGrid(r *rand.Rand, w, h, density int) *BoolGrid {
+	g := NewBoolGrid(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g.SetOpaque(x, y, r.Intn(density) == 0)
+		}
+	}
+	return g
+}
+
+// sameVisible fails the test if got and want don't have exactly the same visible cells, naming the first cell they
+// disagree on
+func sameVisible(t *testing.T, got, want *View, msg string, args ...interface{}) {
+	t.Helper()
+	for k := range want.Visible {
+		if _, ok := got.Visible[k]; !ok {
+			x, y := unpack(k)
+			t.Fatalf(msg+": %d,%d should be visible", append(args, x, y)...)
+		}
+	}
+	for k := range got.Visible {
+		if _, ok := want.Visible[k]; !ok {
+			x, y := unpack(k)
+			t.Fatalf(msg+": %d,%d should not be visible", append(args, x, y)...)
+		}
+	}
+}
+
+func TestComputeIsSymmetricOnEmptyGrid(t *testing.T) {
+	g := NewBoolGrid(41, 41)
+	for _, m := range []Metric{Euclidean, Chebyshev, Manhattan} {
+		for r := 0; r <= 20; r++ {
+			v := New(WithMetric(m)).Compute(g, 20, 20, r)
+			for k := range v.Visible {
+				x, y := unpack(k)
+				dx, dy := x-20, y-20
+				for _, p := range []Point{{-dx, dy}, {dx, -dy}, {-dx, -dy}, {dy, dx}} {
+					if !v.IsVisible(20+p.X, 20+p.Y) {
+						t.Fatalf("metric %d radius %d: %d,%d is visible but its reflection %d,%d isn't\n%s",
+							m, r, dx, dy, p.X, p.Y, v.Render(g, 20, 20, r))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestComputeRadiusIsInclusive(t *testing.T) {
+	g := NewBoolGrid(41, 41)
+	for r := 1; r <= 20; r++ {
+		v := New().Compute(g, 20, 20, r)
+		for _, d := range []Point{{r, 0}, {-r, 0}, {0, r}, {0, -r}} {
+			if !v.IsVisible(20+d.X, 20+d.Y) {
+				t.Errorf("radius %d: %d,%d exactly r away should be visible", r, d.X, d.Y)
+			}
+		}
+		if r < 20 && v.IsVisible(20+r+1, 20) {
+			t.Errorf("radius %d: the tile r+1 away should not be visible", r)
+		}
+	}
+}
+
+func TestComputeIntoDoesNotAllocate(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 64, 64, 6)
+	v := ComputeInto(nil, g, 32, 32, 12)
+	positions := []Point{{32, 32}, {20, 40}, {45, 18}, {10, 10}}
+	for _, p := range positions {
+		ComputeInto(v, g, p.X, p.Y, 12)
+	}
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		p := positions[i%len(positions)]
+		ComputeInto(v, g, p.X, p.Y, 12)
+		i++
+	})
+	if allocs != 0 {
+		t.Errorf("recomputing at the same radius allocated %v times per run", allocs)
+	}
+}
+
+func BenchmarkComputeInto(b *testing.B) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 64, 64, 6)
+	v := ComputeInto(nil, g, 32, 32, 12)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ComputeInto(v, g, 32, 32, 12)
+	}
+}
+
+func TestComputeWithoutRadiusSeesOnlyOrigin(t *testing.T) {
+	g := NewBoolGrid(10, 10)
+	for _, r := range []int{0, -1, -50} {
+		v := New().Compute(g, 4, 5, r)
+		if len(v.Visible) != 1 || !v.IsVisible(4, 5) {
+			t.Errorf("radius %d: got %d visible cells, want only the origin", r, len(v.Visible))
+		}
+	}
+}
+
+func TestComputeIterativeMatchesRecursive(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	configs := [][]Option{
+		nil,
+		{WithCornerPeek(PeekTight)},
+		{WithCornerRule(Strict)},
+		{WithMetric(Chebyshev)},
+		{WithMetric(Manhattan)},
+	}
+	for i := 0; i < 300; i++ {
+		g := randomGrid(r, 40, 40, 2+r.Intn(8))
+		px, py, radius := r.Intn(40), r.Intn(40), r.Intn(30)
+		opts := configs[i%len(configs)]
+		want := New(opts...).Compute(g, px, py, radius)
+		got := New(opts...).ComputeIterative(g, px, py, radius)
+		sameVisible(t, got, want, "grid %d from %d,%d radius %d", i, px, py, radius)
+	}
+	// A radius of hundreds of tiles, the case the iterative scan exists for
+	g := randomGrid(r, 600, 600, 40)
+	sameVisible(t, New().ComputeIterative(g, 300, 300, 290), New().Compute(g, 300, 300, 290), "large radius")
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 30, 30, 6)
+	v := New(WithMemory()).Compute(g, 5, 5, 6)
+	want := v.Snapshot()
+	explored := len(v.explored)
+
+	c := v.Clone()
+	sameVisible(t, c, v, "fresh clone")
+	c.Compute(g, 25, 25, 6)
+	sameVisible(t, v, want, "original after computing the clone")
+	if len(v.explored) != explored {
+		t.Errorf("computing the clone changed the explored tiles of the original from %d to %d", explored,
+			len(v.explored))
+	}
+	if !c.IsExplored(5, 5) || !c.IsExplored(25, 25) {
+		t.Error("the clone should remember what the original explored along with its own")
+	}
+
+	v.Compute(g, 15, 5, 6)
+	if c.IsVisible(15, 5) {
+		t.Error("computing the original changed the clone")
+	}
+}
+
+// Run with -race, computing hands snapshots to readers on other goroutines while it carries on computing
+func TestSnapshotConcurrentRead(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 40, 40, 6)
+	snapshots := make(chan *View)
+	done := make(chan int)
+	for i := 0; i < 2; i++ {
+		go func() {
+			n := 0
+			for s := range snapshots {
+				for y := 0; y < 40; y++ {
+					for x := 0; x < 40; x++ {
+						if s.IsVisible(x, y) {
+							n++
+						}
+					}
+				}
+				if x, y, _ := s.Origin(); !s.IsVisible(x, y) {
+					n = -1
+				}
+			}
+			done <- n
+		}()
+	}
+	v := New(WithMemory())
+	for i := 0; i < 200; i++ {
+		s := v.Compute(g, 5+i%30, 5+i/7%30, 8).Snapshot()
+		snapshots <- s
+		snapshots <- s
+	}
+	close(snapshots)
+	for i := 0; i < 2; i++ {
+		if n := <-done; n < 0 {
+			t.Error("a snapshot read on another goroutine didn't see its own origin")
+		}
+	}
+}
+
+func TestFreshViewSeesNothing(t *testing.T) {
+	g := NewBoolGrid(10, 10)
+	for name, v := range map[string]*View{"New": New(), "zero View": {}} {
+		if v.IsVisible(0, 0) || v.IsVisible(5, 5) || v.Count() != 0 || v.IsExplored(5, 5) || v.LightAt(5, 5) != 0 {
+			t.Errorf("%s: a View that was never computed should see nothing", name)
+		}
+		v.ClearMemory()
+		v.Reset()
+		if v.Compute(g, 5, 5, 3); !v.IsVisible(5, 5) || v.Count() != len(Disk(5, 5, 3)) {
+			t.Errorf("%s: computing sees %d tiles, want %d", name, v.Count(), len(Disk(5, 5, 3)))
+		}
+	}
+}
+
+func TestComputeFarFromOrigin(t *testing.T) {
+	near := randomGrid(rand.New(rand.NewSource(1)), 41, 41, 6)
+	computes := map[string]func(v *View, g GridMap, px, py int) *View{
+		"Compute":          func(v *View, g GridMap, px, py int) *View { return v.Compute(g, px, py, 18) },
+		"ComputeSymmetric": func(v *View, g GridMap, px, py int) *View { return v.ComputeSymmetric(g, px, py, 18) },
+		"ComputeEllipse":   func(v *View, g GridMap, px, py int) *View { return v.ComputeEllipse(g, px, py, 18, 11) },
+	}
+	for _, off := range []Point{{1_000_000, -3_000_000}, {-2_000_000_000, 2_000_000_000}} {
+		// The same walls as near, moved by off
+		far := InfiniteGrid(func(x, y int) bool {
+			return near.IsOpaque(x-off.X, y-off.Y)
+		})
+		for name, compute := range computes {
+			want := compute(New(), near, 20, 20)
+			got := compute(New(), far, 20+off.X, 20+off.Y)
+			if len(got.Visible) != len(want.Visible) {
+				t.Fatalf("%s offset by %v: %d tiles are visible, want %d", name, off, len(got.Visible),
+					len(want.Visible))
+			}
+			for k := range want.Visible {
+				if x, y := unpack(k); !got.IsVisible(x+off.X, y+off.Y) {
+					t.Fatalf("%s offset by %v: %d,%d should be visible", name, off, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestComputeMirrorsReflectedWalls(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// The eight ways of reflecting an offset across the axes and both diagonals
+	reflect := func(dx, dy int) [8]Point {
+		return [8]Point{{dx, dy}, {dy, dx}, {-dx, dy}, {-dy, dx}, {dx, -dy}, {dy, -dx}, {-dx, -dy}, {-dy, -dx}}
+	}
+	configs := [][]Option{nil, {WithCornerPeek(PeekTight)}, {WithMetric(Chebyshev)}, {WithCornerRule(Strict)}}
+	for i := 0; i < 200; i++ {
+		// Walls at random in one octant, the same walls reflected into the other seven
+		g := NewBoolGrid(41, 41)
+		for dx := 1; dx <= 20; dx++ {
+			for dy := 0; dy <= dx; dy++ {
+				if r.Intn(2+i%6) == 0 {
+					for _, p := range reflect(dx, dy) {
+						g.SetOpaque(20+p.X, 20+p.Y, true)
+					}
+				}
+			}
+		}
+		opts := configs[i%len(configs)]
+		v := New(opts...).Compute(g, 20, 20, 20)
+		for k := range v.Visible {
+			x, y := unpack(k)
+			for _, p := range reflect(x-20, y-20) {
+				if !v.IsVisible(20+p.X, 20+p.Y) {
+					t.Fatalf("grid %d: %d,%d is visible but its reflection %d,%d isn't\n%s", i, x-20, y-20, p.X,
+						p.Y, v.Render(g, 20, 20, 20))
+				}
+			}
+		}
+	}
+}
+
+func TestComputeReturnsView(t *testing.T) {
+	g := NewBoolGrid(20, 20)
+	computes := map[string]func(v *View) *View{
+		"Compute":             func(v *View) *View { return v.Compute(g, 5, 5, 4) },
+		"ComputeCached":       func(v *View) *View { return v.ComputeCached(g, 5, 5, 4) },
+		"ComputeCone":         func(v *View) *View { return v.ComputeCone(g, 5, 5, 4, 0, 1) },
+		"ComputeConeLight":    func(v *View) *View { return v.ComputeConeLight(g, 5, 5, 4, 0, 1) },
+		"ComputeEllipse":      func(v *View) *View { return v.ComputeEllipse(g, 5, 5, 4, 2) },
+		"ComputeF":            func(v *View) *View { return v.ComputeF(g, 5, 5, 4.5) },
+		"ComputeFootprint":    func(v *View) *View { return v.ComputeFootprint(g, []Point{{5, 5}, {6, 5}}, 4) },
+		"ComputeHeight":       func(v *View) *View { return v.ComputeHeight(g, 5, 5, 4, 1) },
+		"ComputeInRect":       func(v *View) *View { return v.ComputeInRect(g, 5, 5, 4, 0, 0, 8, 8) },
+		"ComputeIterative":    func(v *View) *View { return v.ComputeIterative(g, 5, 5, 4) },
+		"ComputeLight":        func(v *View) *View { return v.ComputeLight(g, 5, 5, 4) },
+		"ComputeLightFalloff": func(v *View) *View { return v.ComputeLightFalloff(g, 5, 5, 4, LinearFalloff) },
+		"ComputeMasked":       func(v *View) *View { return v.ComputeMasked(g, 5, 5, Disk(0, 0, 4)) },
+		"ComputeMetric":       func(v *View) *View { return v.ComputeMetric(g, 5, 5, 4, Chebyshev) },
+		"ComputeOctants":      func(v *View) *View { return v.ComputeOctants(g, 5, 5, 4, []int{1, 2}) },
+		"ComputeOffset":       func(v *View) *View { return v.ComputeOffset(g, 5, 5, 4, 0.25, 0) },
+		"ComputeParallel":     func(v *View) *View { return v.ComputeParallel(g, 5, 5, 4) },
+		"ComputePermissive":   func(v *View) *View { return v.ComputePermissive(g, 5, 5, 4, 2) },
+		"ComputeRadii":        func(v *View) *View { return v.ComputeRadii(g, 5, 5, [8]int{4, 4, 3, 3, 2, 2, 1, 1}) },
+		"ComputeRaycast":      func(v *View) *View { return v.ComputeRaycast(g, 5, 5, 4) },
+		"ComputeRing":         func(v *View) *View { return v.ComputeRing(g, 5, 5, 2, 4) },
+		"ComputeSquare":       func(v *View) *View { return v.ComputeSquare(g, 5, 5, 4) },
+		"ComputeSymmetric":    func(v *View) *View { return v.ComputeSymmetric(g, 5, 5, 4) },
+		"ComputeTeam":         func(v *View) *View { return v.ComputeTeam(g, []Source{{X: 5, Y: 5, R: 4}}) },
+		"ComputeTiered":       func(v *View) *View { return v.ComputeTiered(g, 5, 5, 2, 4) },
+	}
+	for name, compute := range computes {
+		v := New()
+		if got := compute(v); got != v {
+			t.Errorf("%s should return the View it computed, for chaining", name)
+		}
+		if v.Count() == 0 {
+			t.Errorf("%s didn't compute anything", name)
+		}
+	}
+}
+
+func TestCellsInRect(t *testing.T) {
+	g := NewBoolGrid(10, 10)
+	v := New().Compute(g, 5, 5, 2)
+	edge := New().Compute(InfiniteGrid(nil), math.MaxInt32-1, math.MinInt32+1, 1)
+	cases := []struct {
+		name                   string
+		v                      *View
+		minX, minY, maxX, maxY int
+		want                   int
+	}{
+		{"empty", v, 6, 6, 5, 5, 0},
+		{"single cell", v, 5, 5, 5, 5, 1},
+		{"small", v, 4, 4, 5, 5, 4},
+		{"everything", v, 0, 0, 9, 9, len(v.Visible)},
+		{"outside", v, 20, 20, 30, 30, 0},
+		{"far outside", v, math.MaxInt - 5, 0, math.MaxInt, 3, 0},
+		{"whole plane", v, math.MinInt, math.MinInt, math.MaxInt, math.MaxInt, len(v.Visible)},
+		{"to MaxInt", v, 5, 0, math.MaxInt, math.MaxInt, 9},
+		{"edge of int32", edge, math.MaxInt32 - 1, math.MinInt, math.MaxInt, math.MinInt32 + 1, 3},
+		{"edge of int32 whole plane", edge, math.MinInt, math.MinInt, math.MaxInt, math.MaxInt, len(edge.Visible)},
+	}
+	for _, c := range cases {
+		cells := c.v.CellsInRect(c.minX, c.minY, c.maxX, c.maxY)
+		if len(cells) != c.want {
+			t.Errorf("%s: got %d cells, want %d", c.name, len(cells), c.want)
+		}
+		for _, p := range cells {
+			if !c.v.IsVisible(p.X, p.Y) || p.X < c.minX || p.Y < c.minY || p.X > c.maxX || p.Y > c.maxY {
+				t.Errorf("%s: %d,%d isn't a visible cell within the rectangle", c.name, p.X, p.Y)
+			}
+		}
+	}
+}
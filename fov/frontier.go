@@ -0,0 +1,116 @@
+package fov
+
+// neighbors are the offsets of the four orthogonal neighbors of a tile
+var neighbors = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// Frontier returns the rim of the visible region, every visible tile with at least one orthogonal neighbor within
+// grid that isn't visible, in no particular order. This is where a fog of war gradient would be drawn. The grid is
+// only asked for its bounds and not its opacity, so neighbors off the edge of the map don't put a tile on the rim
+func (v *View) Frontier(grid GridMap) []Point {
+	var rim []Point
+	for k := range v.Visible {
+		x, y := unpack(k)
+		for _, n := range neighbors {
+			nx, ny := x+n[0], y+n[1]
+			if grid.InBounds(nx, ny) && !v.IsVisible(nx, ny) {
+				rim = append(rim, Point{x, y})
+				break
+			}
+		}
+	}
+	return rim
+}
+
+// Enclosed reports whether the most recent compute took place inside an enclosed room, one whose visible floor is
+// surrounded entirely by opaque tiles or the edge of the map, which makes for a handy indoors check. Whenever a
+// visible floor tile has a hidden neighbor that isn't opaque, vision could have carried on past it. If that neighbor
+// is within the radius it was hidden by a shadow, so the room has an opening such as a doorway or a corner and is not
+// enclosed. Note that the shadow of a pillar inside the room hides floor just the same and counts as an opening too.
+// If it is beyond the radius, the room may simply be larger than the radius and the answer can't be known, in which
+// case determined is false. The radius is measured using the Metric or DistanceFunc the View was created with
+func (v *View) Enclosed(grid GridMap) (enclosed, determined bool) {
+	if len(v.Visible) == 0 {
+		return false, false
+	}
+	s := scan{px: v.originX, py: v.originY, metric: v.metric, distance: v.distance}
+	determined = true
+	for k := range v.Visible {
+		x, y := unpack(k)
+		if opaque(grid, x, y) {
+			continue
+		}
+		for _, n := range neighbors {
+			nx, ny := x+n[0], y+n[1]
+			if v.IsVisible(nx, ny) || !grid.InBounds(nx, ny) || grid.IsOpaque(nx, ny) {
+				continue
+			}
+			if s.within(nx-v.originX, ny-v.originY, v.radius) {
+				return false, true
+			}
+			determined = false
+		}
+	}
+	return determined, determined
+}
+
+// EdgeKind tells apart the ways in which a tile can lie on the edge of the field of view, see View.EdgeKind
+type EdgeKind int
+
+const (
+	// Hidden tiles aren't visible at all
+	Hidden EdgeKind = iota
+	// Interior tiles are visible, and so is everything just beyond them as far as walls and the radius go
+	Interior
+	// RadiusEdge tiles are visible, but the open tiles just beyond them are out of range
+	RadiusEdge
+	// WallEdge tiles are visible, but a wall or the edge of the map stops vision just beyond them
+	WallEdge
+)
+
+// EdgeKind classifies the tile at x,y by what lies just beyond it as seen from the origin of the most recent compute,
+// for drawing the fog past the reach of a torch differently from the shadow of a wall. The tiles beyond are the
+// neighbors one step further out along each axis the tile is offset on, and diagonally between them, or all eight
+// neighbors for the origin itself. If any of those is opaque or outside of grid the tile is a WallEdge, as is a
+// visible wall itself. Otherwise, if any of them is out of range it is a RadiusEdge, the radius being measured using
+// the Metric or DistanceFunc the View was created with. Neighbors hidden by the shape of a compute, such as the sides
+// of a cone, count as neither
+func (v *View) EdgeKind(grid GridMap, x, y int) EdgeKind {
+	if !v.IsVisible(x, y) {
+		return Hidden
+	}
+	if opaque(grid, x, y) {
+		return WallEdge
+	}
+	s := scan{px: v.originX, py: v.originY, metric: v.metric, distance: v.distance}
+	dx, dy := x-v.originX, y-v.originY
+	sx, sy := sign(dx), sign(dy)
+	kind := Interior
+	for ny := -1; ny <= 1; ny++ {
+		for nx := -1; nx <= 1; nx++ {
+			// Away from the origin, only the neighbors that step further out on the axes the tile is offset on,
+			// and not back or sideways on either axis, lie beyond it
+			if nx == 0 && ny == 0 || (sx != 0 || sy != 0) && (nx != 0 && nx != sx || ny != 0 && ny != sy) {
+				continue
+			}
+			bx, by := x+nx, y+ny
+			if !grid.InBounds(bx, by) || grid.IsOpaque(bx, by) {
+				return WallEdge
+			}
+			if !s.within(dx+nx, dy+ny, v.radius) {
+				kind = RadiusEdge
+			}
+		}
+	}
+	return kind
+}
+
+// sign returns -1, 0 or 1 for negative, zero and positive n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
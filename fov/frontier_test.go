@@ -0,0 +1,28 @@
+package fov
+
+import "testing"
+
+func TestFrontier(t *testing.T) {
+	// A room whose walls are visible but whose outside isn't, seen from a corner so that the map edge touches it
+	g := NewBoolGrid(10, 10)
+	for i := 0; i < 5; i++ {
+		g.SetOpaque(4, i, true)
+		g.SetOpaque(i, 4, true)
+	}
+	v := New().Compute(g, 1, 1, 8)
+	rim := map[Point]bool{}
+	for _, p := range v.Frontier(g) {
+		if !v.IsVisible(p.X, p.Y) {
+			t.Errorf("%d,%d is on the frontier but not visible", p.X, p.Y)
+		}
+		rim[p] = true
+	}
+	for y := 0; y <= 4; y++ {
+		for x := 0; x <= 4; x++ {
+			want := x == 4 || y == 4
+			if rim[Point{x, y}] != want {
+				t.Errorf("%d,%d on the frontier is %v, want %v", x, y, rim[Point{x, y}], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package fov
+
+// NewFuncGrid builds a GridMap out of plain functions, which saves defining a named type when prototyping or when
+// the map data is already reachable from closures. index may be nil for maps that don't wrap, which is the same as
+// an index that returns its arguments unchanged. Otherwise the returned grid also implements IndexedGrid. Likewise
+// the grid implements HeightGrid or TransparentGrid when given WithHeightFunc or WithTransmittanceFunc
+func NewFuncGrid(inBounds, isOpaque func(x, y int) bool, index func(x, y int) (int, int), opts ...GridOption) GridMap {
+	var f gridFuncs
+	for _, opt := range opts {
+		opt(&f)
+	}
+	g := funcGrid{inBounds: inBounds, isOpaque: isOpaque}
+	// Only the methods of a type count towards the interfaces it implements, so each combination of optional
+	// functions needs a type of its own
+	i, h, t := indexFunc(index), f.height, f.transmittance
+	switch {
+	case i != nil && h != nil && t != nil:
+		return struct {
+			funcGrid
+			indexFunc
+			heightFunc
+			transmittanceFunc
+		}{g, i, h, t}
+	case i != nil && h != nil:
+		return struct {
+			funcGrid
+			indexFunc
+			heightFunc
+		}{g, i, h}
+	case i != nil && t != nil:
+		return struct {
+			funcGrid
+			indexFunc
+			transmittanceFunc
+		}{g, i, t}
+	case h != nil && t != nil:
+		return struct {
+			funcGrid
+			heightFunc
+			transmittanceFunc
+		}{g, h, t}
+	case i != nil:
+		return struct {
+			funcGrid
+			indexFunc
+		}{g, i}
+	case h != nil:
+		return struct {
+			funcGrid
+			heightFunc
+		}{g, h}
+	case t != nil:
+		return struct {
+			funcGrid
+			transmittanceFunc
+		}{g, t}
+	}
+	return g
+}
+
+// GridOption adds an optional function to the grid built by NewFuncGrid
+type GridOption func(*gridFuncs)
+
+// gridFuncs holds the optional functions given to NewFuncGrid
+type gridFuncs struct {
+	height        heightFunc
+	transmittance transmittanceFunc
+}
+
+// WithHeightFunc makes the grid built by NewFuncGrid a HeightGrid whose Height is height
+func WithHeightFunc(height func(x, y int) int) GridOption {
+	return func(f *gridFuncs) {
+		f.height = height
+	}
+}
+
+// WithTransmittanceFunc makes the grid built by NewFuncGrid a TransparentGrid whose Transmittance is transmittance
+func WithTransmittanceFunc(transmittance func(x, y int) float64) GridOption {
+	return func(f *gridFuncs) {
+		f.transmittance = transmittance
+	}
+}
+
+// funcGrid is the GridMap returned by NewFuncGrid
+type funcGrid struct {
+	inBounds, isOpaque func(x, y int) bool
+}
+
+func (g funcGrid) InBounds(x, y int) bool {
+	return g.inBounds(x, y)
+}
+
+func (g funcGrid) IsOpaque(x, y int) bool {
+	return g.isOpaque(x, y)
+}
+
+// indexFunc, heightFunc and transmittanceFunc are embedded alongside a funcGrid to add their methods to it
+type (
+	indexFunc         func(x, y int) (int, int)
+	heightFunc        func(x, y int) int
+	transmittanceFunc func(x, y int) float64
+)
+
+func (f indexFunc) Index(x, y int) (int, int) {
+	return f(x, y)
+}
+
+func (f heightFunc) Height(x, y int) int {
+	return f(x, y)
+}
+
+func (f transmittanceFunc) Transmittance(x, y int) float64 {
+	return f(x, y)
+}
+
+// InfiniteGrid is a GridMap without edges, for procedurally generated worlds that go on forever. Every tile is in
+// bounds and is opaque whenever the function reports it to be, a nil InfiniteGrid being an endless open plain. A
+// compute never looks further from the player than its radius along either axis, so it always finishes no matter
+// how far the world extends, and on open ground the result is exactly the Disk of that radius
+type InfiniteGrid func(x, y int) bool
+
+// InBounds always reports true, there is no edge to an InfiniteGrid
+func (g InfiniteGrid) InBounds(x, y int) bool {
+	return true
+}
+
+// IsOpaque reports whether the function considers x,y opaque
+func (g InfiniteGrid) IsOpaque(x, y int) bool {
+	return g != nil && g(x, y)
+}
+
+// BoolGrid is a ready made GridMap for the common case of a rectangular map backed by a flat slice of opacity flags,
+// stored row by row. Every tile starts out transparent
+type BoolGrid struct {
+	w, h   int
+	opaque []bool
+}
+
+// NewBoolGrid returns a transparent BoolGrid that is w tiles wide and h tiles tall, with 0,0 in the top left corner
+func NewBoolGrid(w, h int) *BoolGrid {
+	return &BoolGrid{w: w, h: h, opaque: make([]bool, w*h)}
+}
+
+// SetOpaque marks the tile at x,y as blocking vision or not. Coordinates outside the grid are ignored
+func (g *BoolGrid) SetOpaque(x, y int, opaque bool) {
+	if g.InBounds(x, y) {
+		g.opaque[y*g.w+x] = opaque
+	}
+}
+
+// InBounds reports whether x,y lies within the grid
+func (g *BoolGrid) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.w && y < g.h
+}
+
+// IsOpaque reports whether the tile at x,y blocks vision. Tiles outside the grid never do
+func (g *BoolGrid) IsOpaque(x, y int) bool {
+	return g.InBounds(x, y) && g.opaque[y*g.w+x]
+}
@@ -0,0 +1,32 @@
+package fov
+
+import "testing"
+
+func TestInfiniteGridIsDisk(t *testing.T) {
+	for r := 0; r <= 15; r++ {
+		px, py := -1000, 500
+		asked := 0
+		g := InfiniteGrid(func(x, y int) bool {
+			if abs(x-px) > r || abs(y-py) > r {
+				asked++
+			}
+			return false
+		})
+		v := New().Compute(g, px, py, r)
+		disk := Disk(px, py, r)
+		if len(v.Visible) != len(disk) {
+			t.Fatalf("radius %d: %d tiles are visible, want the %d of the disk", r, len(v.Visible), len(disk))
+		}
+		for _, p := range disk {
+			if !v.IsVisible(p.X, p.Y) {
+				t.Fatalf("radius %d: %d,%d of the disk isn't visible", r, p.X, p.Y)
+			}
+		}
+		if asked != 0 {
+			t.Errorf("radius %d: the grid was asked about %d tiles beyond the radius", r, asked)
+		}
+	}
+	if v := New().Compute(InfiniteGrid(nil), 3, 3, 6); len(v.Visible) != len(Disk(3, 3, 6)) {
+		t.Errorf("a nil InfiniteGrid sees %d tiles, want %d", len(v.Visible), len(Disk(3, 3, 6)))
+	}
+}
@@ -0,0 +1,53 @@
+package fov
+
+// HeightGrid is a GridMap whose tiles have an elevation, so that low obstacles like crates only hide what is lower
+// than them and behind them rather than blocking vision outright. Heights are in whatever units the map likes, as
+// long as they are the same units as the eye height passed to ComputeHeight
+type HeightGrid interface {
+	GridMap
+	Height(x, y int) int
+}
+
+// ComputeHeight is Compute for a 2.5D map. The viewer's eye sits eyeHeight above the height of their own tile, and a
+// tile is only visible if no tile on the line between them is at least as high as the line of sight from the eye to
+// the top of the target at that point. A low crate therefore hides the floor right behind it while a tall tower
+// further back can still be seen over it. eyeHeight should be positive, an eye level with flat ground can't see past
+// the tile in front of it. Opaque tiles still block vision regardless of their height, and grids that don't implement
+// HeightGrid are computed exactly as Compute would
+func (v *View) ComputeHeight(grid GridMap, px, py, radius, eyeHeight int) *View {
+	s := v.newScan(grid, px, py, radius)
+	if height, ok := grid.(HeightGrid); ok {
+		x, y := s.at(0, 0)
+		s.height, s.eye = height, eyeHeight
+		if grid.InBounds(x, y) {
+			s.eye += height.Height(x, y)
+		}
+	}
+	v.run(&s)
+	return v
+}
+
+// overlooks reports whether the line of sight from the eye to the top of the tile offset dx,dy from the player clears
+// every tile in between
+func (s *scan) overlooks(dx, dy int) bool {
+	tx, ty := s.at(dx, dy)
+	if !s.grid.InBounds(tx, ty) {
+		return true
+	}
+	// The line of sight drops (or rises) linearly from the eye to the target over steps tiles, compared in integers
+	// by scaling both sides by steps
+	steps := max(abs(dx), abs(dy))
+	target := s.height.Height(tx, ty)
+	i := -1
+	return walkLine(0, 0, dx, dy, func(lx, ly int) bool {
+		i++
+		if i == 0 || (lx == dx && ly == dy) {
+			return true
+		}
+		x, y := s.at(lx, ly)
+		if !s.grid.InBounds(x, y) {
+			return true
+		}
+		return s.height.Height(x, y)*steps < s.eye*steps+(target-s.eye)*i
+	})
+}
@@ -0,0 +1,12 @@
+package fov
+
+// ComputeIterative produces exactly the same result as Compute, but scans each octant from an explicit stack of
+// rows rather than through recursion. Very large radii would otherwise recurse hundreds of calls deep, the stack is
+// kept by the View and reused so that this costs no more allocation than Compute does. It always uses recursive
+// shadowcasting, even for a View configured WithSymmetric
+func (v *View) ComputeIterative(grid GridMap, px, py, radius int) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.symmetric, s.iterative = false, true
+	v.run(&s)
+	return v
+}
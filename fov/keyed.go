@@ -0,0 +1,46 @@
+package fov
+
+// KeyedGrid is a GridMap that identifies its own cells, for maps where something other than an x,y pair is the
+// natural way to refer to a tile, such as a tile ID or a compact index
+type KeyedGrid[K comparable] interface {
+	GridMap
+	Key(x, y int) K
+}
+
+// KeyedView is the counterpart of View for a KeyedGrid, holding the visible set keyed by the grid's own cell
+// identifiers instead of by coordinates. The shadowcasting itself is done by an ordinary View, so every Option
+// applies, and the result is translated through Key once the compute is done
+type KeyedView[K comparable] struct {
+	Visible map[K]struct{}
+
+	view *View
+}
+
+// NewKeyed returns a KeyedView configured by any options provided, exactly as New would
+func NewKeyed[K comparable](opts ...Option) *KeyedView[K] {
+	return &KeyedView[K]{Visible: make(map[K]struct{}), view: New(opts...)}
+}
+
+// Compute updates the visible set from px,py within radius, see View.Compute
+func (v *KeyedView[K]) Compute(grid KeyedGrid[K], px, py, radius int) *KeyedView[K] {
+	v.view.Compute(grid, px, py, radius)
+	for k := range v.Visible {
+		delete(v.Visible, k)
+	}
+	for k := range v.view.Visible {
+		x, y := unpack(k)
+		v.Visible[grid.Key(x, y)] = struct{}{}
+	}
+	return v
+}
+
+// IsVisible reports whether the cell identified by k is visible
+func (v *KeyedView[K]) IsVisible(k K) bool {
+	_, ok := v.Visible[k]
+	return ok
+}
+
+// View returns the View that does the computing, which still holds the visible set keyed by coordinates
+func (v *KeyedView[K]) View() *View {
+	return v.view
+}
@@ -0,0 +1,86 @@
+package fov
+
+// Falloff maps the distance of a visible tile from the light source, along with the radius of the light, to a
+// brightness. Results outside of [0, 1] are clamped
+type Falloff func(dist, radius float64) float64
+
+// LinearFalloff dims light evenly from full brightness at the source down to nothing at the radius
+func LinearFalloff(dist, radius float64) float64 {
+	if radius <= 0 {
+		return 1
+	}
+	return 1 - dist/radius
+}
+
+// ComputeLight performs a Compute and also records how brightly each visible tile is lit, using LinearFalloff.
+// The brightness of a tile can then be retrieved with LightAt
+func (v *View) ComputeLight(grid GridMap, px, py, radius int) *View {
+	return v.ComputeLightFalloff(grid, px, py, radius, LinearFalloff)
+}
+
+// ComputeLightFalloff is ComputeLight with a custom Falloff in place of LinearFalloff
+func (v *View) ComputeLightFalloff(grid GridMap, px, py, radius int, f Falloff) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.falloff = f
+	v.run(&s)
+	return v
+}
+
+// LightAt returns the brightness, within [0, 1], of the tile at x,y as of the last ComputeLight. Tiles that aren't
+// visible, or any tile after a compute that didn't record light, have a brightness of 0
+func (v *View) LightAt(x, y int) float64 {
+	return v.light[key(x, y)]
+}
+
+// LightByte is LightAt scaled to a whole number from 0 to 255, rounded to the nearest, for renderers that look up
+// a palette entry or a shade per tile. Tiles that aren't lit are 0 and fully lit tiles are 255
+func (v *View) LightByte(x, y int) uint8 {
+	return uint8(v.LightAt(x, y)*255 + 0.5)
+}
+
+// brightness evaluates f and clamps the result to [0, 1]
+func brightness(f Falloff, dist, radius float64) float64 {
+	b := f(dist, radius)
+	switch {
+	case b < 0:
+		return 0
+	case b > 1:
+		return 1
+	}
+	return b
+}
+
+// Illumination is how well lit a tile is after ComputeTiered
+type Illumination int
+
+const (
+	// Dark tiles can't be seen at all
+	Dark Illumination = iota
+	// Dim tiles are visible, but beyond the bright radius
+	Dim
+	// Bright tiles are visible and within the bright radius
+	Bright
+)
+
+// ComputeTiered models a light source with a bright radius and a dim radius beyond it, in the style of tabletop
+// RPGs. A single shadowcast is performed out to rDim, and every visible tile is then recorded in either Bright or Dim
+// depending on whether it lies within rBright. Visible holds both, and LightLevel reports which a tile ended up in
+func (v *View) ComputeTiered(grid GridMap, px, py, rBright, rDim int) *View {
+	s := v.newScan(grid, px, py, rDim)
+	s.tiered, s.brightRadius = true, rBright
+	v.run(&s)
+	return v
+}
+
+// LightLevel returns the Illumination of the tile at x,y as of the last ComputeTiered. Any tile after a compute that
+// didn't record tiers is Dark
+func (v *View) LightLevel(x, y int) Illumination {
+	k := key(x, y)
+	if _, ok := v.Bright[k]; ok {
+		return Bright
+	}
+	if _, ok := v.Dim[k]; ok {
+		return Dim
+	}
+	return Dark
+}
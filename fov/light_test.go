@@ -0,0 +1,31 @@
+package fov
+
+import "testing"
+
+func TestComputeLightCountsAxisTilesOnce(t *testing.T) {
+	g := NewBoolGrid(21, 21)
+	v := New()
+	revealed := map[Point]int{}
+	v.OnReveal = func(x, y int) {
+		revealed[Point{x, y}]++
+	}
+	// A flat falloff, so that light added twice to a tile would show up as twice as bright
+	v.ComputeLightFalloff(g, 10, 10, 8, func(dist, radius float64) float64 {
+		return 0.25
+	})
+	if revealed[Point{13, 10}] != 1 {
+		t.Errorf("the tile 3 along the +X axis was revealed %d times, want once", revealed[Point{13, 10}])
+	}
+	for k := range v.Visible {
+		x, y := unpack(k)
+		if l := v.LightAt(x, y); l != 0.25 {
+			t.Fatalf("%d,%d is lit %v, want 0.25", x, y, l)
+		}
+		if revealed[Point{x, y}] != 1 {
+			t.Fatalf("%d,%d was revealed %d times, want once", x, y, revealed[Point{x, y}])
+		}
+	}
+	if v.ComputeLight(g, 10, 10, 8); v.LightAt(14, 10) != LinearFalloff(4, 8) {
+		t.Errorf("the tile 4 along the +X axis is lit %v, want %v", v.LightAt(14, 10), LinearFalloff(4, 8))
+	}
+}
@@ -0,0 +1,112 @@
+package fov
+
+// LineOfSight reports whether there is an unobstructed Bresenham line between x0,y0 and x1,y1. Only the tiles in
+// between are checked, so the endpoints themselves never block, e.g. a creature standing in a doorway can still be
+// shot at. Unlike Compute there is no radius and no visible set is built, which makes this the cheaper choice when
+// only a single pair of tiles is of interest
+func LineOfSight(grid GridMap, x0, y0, x1, y1 int) bool {
+	return walkLine(x0, y0, x1, y1, func(x, y int) bool {
+		if (x == x0 && y == y0) || (x == x1 && y == y1) {
+			return true
+		}
+		return !opaque(grid, x, y)
+	})
+}
+
+// FirstBlocker walks the same line as LineOfSight from x0,y0 towards x1,y1 and returns the first opaque tile in the
+// way, e.g. the wall an arrow hits. Like LineOfSight the endpoints never block, so ok is false exactly when
+// LineOfSight would report a clear line
+func FirstBlocker(grid GridMap, x0, y0, x1, y1 int) (p Point, ok bool) {
+	walkLine(x0, y0, x1, y1, func(x, y int) bool {
+		if (x == x0 && y == y0) || (x == x1 && y == y1) || !opaque(grid, x, y) {
+			return true
+		}
+		p, ok = Point{x, y}, true
+		return false
+	})
+	return p, ok
+}
+
+// CanSee reports whether a viewer at ax,ay can see bx,by within radius r. The answer is guaranteed to be symmetric,
+// CanSee(a, b) always equals CanSee(b, a), which is not true of the visible sets produced by Compute. Bresenham
+// lines are not symmetric on their own, so the line is always walked starting from whichever of the two points
+// sorts first by x and then y
+func CanSee(grid GridMap, ax, ay, bx, by, r int) bool {
+	if !Euclidean.inRadius(bx-ax, by-ay, r) {
+		return false
+	}
+	if bx < ax || (bx == ax && by < ay) {
+		ax, ay, bx, by = bx, by, ax, ay
+	}
+	return LineOfSight(grid, ax, ay, bx, by)
+}
+
+// CanSeeTile reports whether there is a clear line from the center of the tile at px,py to the center of the tile at
+// tx,ty within radius r, for asking whether the player sees a creature rather than merely the tile it stands on. A
+// wall is visible as soon as its face is, so a creature hiding behind one can't be found by checking the wall tile,
+// but here the opacity of the target tile is ignored and only the tiles in between are checked. Unlike the Bresenham
+// line of CanSee, the line is the exact one between the two centers, which is symmetric all by itself. A line that
+// passes exactly through the corner where four tiles meet only crosses the two diagonal ones, as in ComputePermissive
+func CanSeeTile(grid GridMap, px, py, tx, ty, r int) bool {
+	dx, dy := tx-px, ty-py
+	if !Euclidean.inRadius(dx, dy, r) {
+		return false
+	}
+	if dx == 0 && dy == 0 {
+		return true
+	}
+	index, _ := grid.(IndexedGrid)
+	s := scan{grid: grid, index: index, px: px, py: py}
+	// At a scale of 2, tile centers lie on even coordinates
+	return s.sightline(0, 0, 2*dx, 2*dy, 1, dx, dy)
+}
+
+// Line returns the points of the Bresenham line from x0,y0 to x1,y1, both endpoints included, which is the same line
+// LineOfSight checks. The points are in order from the start to the end and consecutive points are always neighbors,
+// diagonally or otherwise. The same pair of endpoints always gives the same line, but swapping them may not give the
+// same points in reverse, see CanSee
+func Line(x0, y0, x1, y1 int) []Point {
+	points := make([]Point, 0, max(abs(x1-x0), abs(y1-y0))+1)
+	walkLine(x0, y0, x1, y1, func(x, y int) bool {
+		points = append(points, Point{x, y})
+		return true
+	})
+	return points
+}
+
+// walkLine steps along the Bresenham line from x0,y0 to x1,y1, calling fn for every point on it including both
+// endpoints. The walk stops as soon as fn returns false, in which case walkLine also returns false
+func walkLine(x0, y0, x1, y1 int, fn func(x, y int) bool) bool {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		if !fn(x0, y0) {
+			return false
+		}
+		if x0 == x1 && y0 == y1 {
+			return true
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// opaque reports whether the tile at x,y blocks vision. Tiles outside the grid never do, and IsOpaque is only ever
+// consulted for tiles that are in bounds
+func opaque(grid GridMap, x, y int) bool {
+	return grid.InBounds(x, y) && grid.IsOpaque(x, y)
+}
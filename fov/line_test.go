@@ -0,0 +1,47 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCanSeeIsSymmetric(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		g := randomGrid(r, 20, 20, 4)
+		for j := 0; j < 50; j++ {
+			ax, ay, bx, by := r.Intn(20), r.Intn(20), r.Intn(20), r.Intn(20)
+			if CanSee(g, ax, ay, bx, by, 12) != CanSee(g, bx, by, ax, ay, 12) {
+				t.Fatalf("CanSee from %d,%d to %d,%d differs from the reverse", ax, ay, bx, by)
+			}
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	cases := []struct {
+		x0, y0, x1, y1 int
+		want           []Point
+	}{
+		{0, 0, 0, 0, []Point{{0, 0}}},
+		{0, 0, 3, 3, []Point{{0, 0}, {1, 1}, {2, 2}, {3, 3}}},
+		{2, 2, -1, -1, []Point{{2, 2}, {1, 1}, {0, 0}, {-1, -1}}},
+		{0, 0, -3, 3, []Point{{0, 0}, {-1, 1}, {-2, 2}, {-3, 3}}},
+		{5, 0, 8, -3, []Point{{5, 0}, {6, -1}, {7, -2}, {8, -3}}},
+		{0, 0, 4, 2, []Point{{0, 0}, {1, 1}, {2, 1}, {3, 2}, {4, 2}}},
+		{0, 0, 0, -3, []Point{{0, 0}, {0, -1}, {0, -2}, {0, -3}}},
+	}
+	for _, c := range cases {
+		got := Line(c.x0, c.y0, c.x1, c.y1)
+		if len(got) != len(c.want) {
+			t.Errorf("Line(%d, %d, %d, %d) = %v, want %v", c.x0, c.y0, c.x1, c.y1, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("Line(%d, %d, %d, %d) = %v, want %v", c.x0, c.y0, c.x1, c.y1, got, c.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package fov
+
+// ComputeMasked is Compute with the shape of the field of view given by mask, the offsets from px,py of the only
+// tiles that may be visible, in place of a radius. Walls cast their shadows as usual, whether or not they are part of
+// the mask themselves, and tiles outside of grid are left out as usual too. Any shape will do, such as a Disk or an
+// ellipse worked out once ahead of time, e.g. Disk(0, 0, r) for a mask centered on the origin that matches a Compute
+// of radius r. The player's own tile is always visible, and Origin reports the furthest the mask reaches along
+// either axis as the radius
+func (v *View) ComputeMasked(grid GridMap, px, py int, mask []Point) *View {
+	if v.mask == nil {
+		v.mask = make(CellSet, len(mask))
+	}
+	v.mask.clear()
+	depth := 0
+	for _, p := range mask {
+		v.mask.Add(p.X, p.Y)
+		depth = max(depth, max(abs(p.X), abs(p.Y)))
+	}
+	s := v.newScan(grid, px, py, depth)
+	s.mask = v.mask
+	v.run(&s)
+	return v
+}
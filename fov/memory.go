@@ -0,0 +1,70 @@
+package fov
+
+// memory maps every explored cell, keyed just like a CellSet, to the turn it was last seen on
+type memory map[int64]int
+
+// clear empties the memory in place, keeping its storage around for reuse
+func (m memory) clear() {
+	for k := range m {
+		delete(m, k)
+	}
+}
+
+// copy returns an independent copy of the memory, or nil if the memory is nil
+func (m memory) copy() memory {
+	if m == nil {
+		return nil
+	}
+	c := make(memory, len(m))
+	for k, turn := range m {
+		c[k] = turn
+	}
+	return c
+}
+
+// WithMemory turns on tracking of explored tiles and returns v so that it can be chained onto New, for Views that
+// were created without the WithMemory option. From then on every compute adds the tiles it finds visible to the
+// explored tiles, which are never forgotten by computing again. This is the classic roguelike "memory" of tiles that
+// have been seen before but are not currently in view, see IsExplored and LastSeen
+func (v *View) WithMemory() *View {
+	v.remember = true
+	if v.explored == nil {
+		v.explored = make(memory)
+	}
+	return v
+}
+
+// IsExplored reports whether the tile at x,y has been visible at any point since memory was enabled, or since the
+// last call to ClearMemory
+func (v *View) IsExplored(x, y int) bool {
+	_, ok := v.explored[key(x, y)]
+	return ok
+}
+
+// SetTurn sets the turn that every following compute records as the turn it saw its tiles on, see LastSeen. The
+// turn is entirely up to the caller, usually a counter that goes up by one every game turn, and starts out as 0
+func (v *View) SetTurn(turn int) {
+	v.turn = turn
+}
+
+// LastSeen returns the turn the tile at x,y was last visible on, as set by SetTurn, which makes it possible to fade
+// out tiles that haven't been seen in a while. ok is false when the tile hasn't been explored at all
+func (v *View) LastSeen(x, y int) (turn int, ok bool) {
+	turn, ok = v.explored[key(x, y)]
+	return turn, ok
+}
+
+// ClearMemory forgets every explored tile, for example when the player moves to a new level. Memory stays enabled
+func (v *View) ClearMemory() {
+	v.explored.clear()
+}
+
+// memorize adds the current visible set to the explored tiles when memory is enabled
+func (v *View) memorize() {
+	if !v.remember {
+		return
+	}
+	for k := range v.Visible {
+		v.explored[k] = v.turn
+	}
+}
@@ -0,0 +1,123 @@
+package fov
+
+import "math"
+
+// Metric selects how distance from the player is measured when deciding whether a tile falls within the
+// radius of a compute. It also determines the overall shape of the field of view on an open map
+type Metric int
+
+const (
+	// Euclidean is straight line distance and produces a circular field of view. This is the default
+	Euclidean Metric = iota
+	// Chebyshev counts diagonal steps the same as orthogonal ones, matching 8-directional movement, and
+	// produces a square field of view
+	Chebyshev
+	// Manhattan counts only orthogonal steps, matching 4-directional movement, and produces a diamond
+	// shaped field of view
+	Manhattan
+)
+
+// inRadius reports whether a tile offset dx,dy from the player is within radius r under the metric. The radius is
+// inclusive, a tile exactly r away is in range
+func (m Metric) inRadius(dx, dy, r int) bool {
+	switch m {
+	case Chebyshev:
+		return max(abs(dx), abs(dy)) <= r
+	case Manhattan:
+		return abs(dx)+abs(dy) <= r
+	default:
+		return distSq(0, 0, dx, dy) <= int64(r)*int64(r)
+	}
+}
+
+// belowRadius reports whether a tile offset dx,dy from the player is strictly closer than r under the metric
+func (m Metric) belowRadius(dx, dy, r int) bool {
+	switch m {
+	case Chebyshev:
+		return max(abs(dx), abs(dy)) < r
+	case Manhattan:
+		return abs(dx)+abs(dy) < r
+	default:
+		return distSq(0, 0, dx, dy) < int64(r)*int64(r)
+	}
+}
+
+// distance returns how far away a tile offset dx,dy from the player is under the metric
+func (m Metric) distance(dx, dy int) float64 {
+	switch m {
+	case Chebyshev:
+		return float64(max(abs(dx), abs(dy)))
+	case Manhattan:
+		return float64(abs(dx) + abs(dy))
+	default:
+		return math.Sqrt(float64(distSq(0, 0, dx, dy)))
+	}
+}
+
+// DistanceFunc measures the distance between two tiles for the radius check of a compute, for rules that none of the
+// Metrics describe, such as hex grids stored in offset coordinates. It is given the player's position as x0,y0 and
+// the tile being checked as x1,y1. A tile is within the radius r when the distance is at most r, but tiles further
+// than r from the player along either axis are never scanned, so the function should never measure a tile as closer
+// than that
+type DistanceFunc func(x0, y0, x1, y1 int) int
+
+// within reports whether the tile offset dx,dy from the player is within radius r, measured using the scan's
+// DistanceFunc if it has one and its Metric otherwise
+func (s *scan) within(dx, dy, r int) bool {
+	if s.distance != nil {
+		return s.distance(s.px, s.py, s.px+dx, s.py+dy) <= r
+	}
+	return s.metric.inRadius(dx, dy, r)
+}
+
+// closer reports whether the tile offset dx,dy from the player is strictly closer than r, see within
+func (s *scan) closer(dx, dy, r int) bool {
+	if s.distance != nil {
+		return s.distance(s.px, s.py, s.px+dx, s.py+dy) < r
+	}
+	return s.metric.belowRadius(dx, dy, r)
+}
+
+// dist returns how far away the tile offset dx,dy from the player is, see within
+func (s *scan) dist(dx, dy int) float64 {
+	if s.distance != nil {
+		return float64(s.distance(s.px, s.py, s.px+dx, s.py+dy))
+	}
+	return s.metric.distance(dx, dy)
+}
+
+// steps returns the distance of the tile offset dx,dy from the player as the smallest radius that reaches it, see
+// within. Only straight line distance isn't a whole number to begin with, it is rounded up
+func (s *scan) steps(dx, dy int) int {
+	switch {
+	case s.distance != nil:
+		return s.distance(s.px, s.py, s.px+dx, s.py+dy)
+	case s.metric == Euclidean:
+		d := distSq(0, 0, dx, dy)
+		r := int64(math.Ceil(math.Sqrt(float64(d))))
+		// The square root may be off by a hair for large distances, settle on the exact answer
+		for r > 0 && (r-1)*(r-1) >= d {
+			r--
+		}
+		for r*r < d {
+			r++
+		}
+		return int(r)
+	default:
+		return int(s.metric.distance(dx, dy))
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+package fov
+
+// Octant returns which of the eight octants, numbered 1 to 8 as in the scans performed by Compute, the visible tile
+// at x,y belongs to relative to the origin of the most recent compute. Tiles along an axis or a diagonal lie on the
+// border of two octants, those get the lower of the two numbers. The origin itself, and any tile that isn't visible,
+// returns 0
+func (v *View) Octant(x, y int) int {
+	if !v.IsVisible(x, y) {
+		return 0
+	}
+	return octantOf(x-v.originX, y-v.originY)
+}
+
+// octantOf is the inverse of distHeightXY, returning the lowest numbered octant that covers the offset dx,dy, or 0
+// for no offset at all
+func octantOf(dx, dy int) int {
+	for oct := 1; oct <= 8; oct++ {
+		d, h := dx, dy
+		if oct&0x4 > 0 {
+			d, h = dy, dx
+		}
+		if oct&0x1 > 0 {
+			d = -d
+		}
+		if oct&0x2 > 0 {
+			h = -h
+		}
+		if d >= 1 && h >= 0 && h <= d {
+			return oct
+		}
+	}
+	return 0
+}
+
+// ComputeRadii is Compute with a radius of its own for each of the eight octants, numbered 1 to 8 as returned by
+// Octant, with radii[i-1] being the radius of octant i. Seeing further ahead than behind makes for a cheap vignette
+// with a shape of its own, without any of the angles of ComputeCone. The tiles along an axis or a diagonal belong
+// to two octants and are visible if either of them reaches, and an octant with a negative radius sees nothing. When
+// every radius is the same the result is identical to Compute, and Origin reports the largest of them. Since the
+// octants only exist in recursive shadowcasting, it is used even if the View was created WithSymmetric
+func (v *View) ComputeRadii(grid GridMap, px, py int, radii [8]int) *View {
+	radius := radii[0]
+	for _, r := range radii[1:] {
+		radius = max(radius, r)
+	}
+	s := v.newScan(grid, px, py, radius)
+	s.symmetric = false
+	s.perOctant, s.radii = true, radii
+	v.run(&s)
+	return v
+}
+
+// ComputeOctants is Compute limited to the given octants, numbered 1 to 8 as returned by Octant, for a viewer that
+// can only look in certain directions, e.g. octants 1 and 3 for a 90 degree wedge facing west. It is a cheaper
+// alternative to ComputeCone because octants outside the wedge are never scanned at all. Numbers outside 1 to 8 are
+// ignored, and the player's own tile is always visible. Since the octants only exist in recursive shadowcasting, it is
+// used even if the View was created WithSymmetric. Computing all eight octants is identical to Compute
+func (v *View) ComputeOctants(grid GridMap, px, py, radius int, octants []int) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.symmetric = false
+	for _, oct := range octants {
+		if oct >= 1 && oct <= 8 {
+			s.octants |= 1 << uint(oct)
+		}
+	}
+	if s.octants == 0 {
+		// None of the octants asked for exist, which still has to leave the scan with nothing but the origin
+		s.depth = 0
+	}
+	v.run(&s)
+	return v
+}
@@ -0,0 +1,72 @@
+package fov
+
+import "math"
+
+// eyeLimit is the furthest the eye may be moved from the center of the player's tile along either axis, just short
+// of its edge so that every tile beyond the player's own lies at some positive distance from the eye
+var eyeLimit = math.Nextafter(0.5, 0)
+
+// ComputeOffset is Compute with the eye moved ox,oy away from the center of the player's tile, for viewers that
+// stand or lean off center like an entity between tiles or one peeking around a corner. The lines of sight, and with
+// them the shadows, start from px+ox,py+oy, while the radius is still measured from the player's tile. Offsets are
+// clamped to the player's tile, which spans half a tile either way. Offsets of 0 are identical to Compute. Since the
+// eye only moves in recursive shadowcasting, it is used even if the View was created WithSymmetric
+func (v *View) ComputeOffset(grid GridMap, px, py, radius int, ox, oy float64) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.symmetric = false
+	s.ox, s.oy = clampEye(ox), clampEye(oy)
+	v.run(&s)
+	return v
+}
+
+// clampEye keeps an eye offset within eyeLimit, treating NaN as no offset at all
+func clampEye(o float64) float64 {
+	switch {
+	case o != o:
+		return 0
+	case o > eyeLimit:
+		return eyeLimit
+	case o < -eyeLimit:
+		return -eyeLimit
+	}
+	return o
+}
+
+// firstRow returns the distance of the first row of octant oct to scan. That is normally the row next to the player,
+// but once the eye has been moved back from the player's own row, the walls either side of the player in that row
+// lie ahead of the eye and can cast shadows into the octant too
+func (s *scan) firstRow(oct int) int {
+	if s.ox != 0 || s.oy != 0 {
+		if od, _ := s.eyeIn(oct); od < 0 {
+			return 0
+		}
+	}
+	return 1
+}
+
+// signF returns -1, 0 or 1 for negative, zero and positive o
+func signF(o float64) int {
+	switch {
+	case o < 0:
+		return -1
+	case o > 0:
+		return 1
+	}
+	return 0
+}
+
+// eyeIn converts the eye offset of the scan into the distance and height axes of an octant, the inverse of how
+// distHeightXY maps them back
+func (s *scan) eyeIn(oct int) (od, oh float64) {
+	od, oh = s.ox, s.oy
+	if oct&0x4 > 0 {
+		od, oh = s.oy, s.ox
+	}
+	if oct&0x1 > 0 {
+		od = -od
+	}
+	if oct&0x2 > 0 {
+		oh = -oh
+	}
+	return od, oh
+}
@@ -0,0 +1,66 @@
+package fov
+
+// Option configures a View when passed to New
+type Option func(*View)
+
+// WithMetric measures the radius of every compute using m instead of straight line distance
+func WithMetric(m Metric) Option {
+	return func(v *View) {
+		v.metric = m
+	}
+}
+
+// WithDistanceFunc measures the radius of every compute using f, in place of any Metric, see DistanceFunc
+func WithDistanceFunc(f DistanceFunc) Option {
+	return func(v *View) {
+		v.distance = f
+	}
+}
+
+// WithDistances makes every compute record how far each visible tile is from the player, see View.DistanceOf
+func WithDistances() Option {
+	return func(v *View) {
+		v.distances = make(map[int64]int)
+	}
+}
+
+// WithMemory enables tracking of explored tiles from the start, see View.WithMemory
+func WithMemory() Option {
+	return func(v *View) {
+		v.WithMemory()
+	}
+}
+
+// WithSymmetric makes every compute use symmetric shadowcasting, see View.ComputeSymmetric
+func WithSymmetric() Option {
+	return func(v *View) {
+		v.symmetric = true
+	}
+}
+
+// WithBlindInWalls makes a player whose own tile is opaque, such as one hiding inside a secret wall, unable to see
+// anything but that tile. By default the player sees out of an opaque tile just as they would out of any other
+func WithBlindInWalls() Option {
+	return func(v *View) {
+		v.blindInWalls = true
+	}
+}
+
+// WithRingOrigin makes ComputeRing keep the player's own tile visible even when it lies inside the hole of the ring,
+// e.g. so that the player is still drawn while blinded up close
+func WithRingOrigin() Option {
+	return func(v *View) {
+		v.ringOrigin = true
+	}
+}
+
+// WithBorderOpaque makes the edge of the map block vision like a solid wall all the way around it. Tiles outside the
+// grid are never visible either way, but by default they are simply passed over, and vision carries on to whatever
+// in bounds tiles lie beyond. With this option each of them casts a shadow instead. On a plain rectangular map the
+// result is the same, since no line of sight ever leaves the rectangle and comes back, but the holes in a map whose
+// InBounds isn't a rectangle, like a cave cut out of the void, then hide whatever lies behind them
+func WithBorderOpaque() Option {
+	return func(v *View) {
+		v.borderOpaque = true
+	}
+}
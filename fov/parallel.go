@@ -0,0 +1,53 @@
+package fov
+
+import "sync"
+
+// ComputeParallel produces the same result as Compute, but scans every octant (or every quadrant, for a View
+// configured WithSymmetric) on its own goroutine. Each goroutine fills a set of its own, and since neighboring octants
+// only ever overlap along the axes and diagonals, these are then simply merged together. The sets are kept by the View
+// and reused by later calls. This only pays off for large radii on machines with cores to spare, since the merge
+// itself is done serially, and the grid must be safe to read from several goroutines at once
+func (v *View) ComputeParallel(grid GridMap, px, py, radius int) *View {
+	s := v.newScan(grid, px, py, radius)
+	if s.depth <= 0 {
+		v.run(&s)
+		return v
+	}
+
+	n := 8
+	if s.symmetric {
+		n = 4
+	}
+	for len(v.parts) < n {
+		v.parts = append(v.parts, New())
+	}
+	parts := v.parts[:n]
+	var wg sync.WaitGroup
+	for i := range parts {
+		if v.distances != nil && parts[i].distances == nil {
+			parts[i].distances = make(map[int64]int)
+		}
+		parts[i].Reset()
+		wg.Add(1)
+		go func(part *View, i int) {
+			defer wg.Done()
+			if s.symmetric {
+				part.quadrant(&s, i, 1, fraction{-1, 1}, fraction{1, 1})
+			} else {
+				part.fov(&s, 1, 0, 1, i+1)
+			}
+		}(parts[i], i)
+	}
+	wg.Wait()
+
+	v.begin(&s)
+	for _, part := range parts {
+		for k := range part.Visible {
+			if v.insert(k) && v.distances != nil {
+				v.distances[k] = part.distances[k]
+			}
+		}
+	}
+	v.memorize()
+	return v
+}
@@ -0,0 +1,68 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComputeParallelMatchesCompute(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	configs := [][]Option{nil, {WithSymmetric()}, {WithCornerRule(Strict)}, {WithDistances()}}
+	for i := 0; i < 200; i++ {
+		g := randomGrid(r, 40, 40, 2+r.Intn(8))
+		px, py, radius := r.Intn(40), r.Intn(40), r.Intn(25)
+		opts := configs[i%len(configs)]
+		want := New(opts...).Compute(g, px, py, radius)
+		got := New(opts...)
+		// Computing twice makes sure the per-octant sets kept by the View are reused correctly
+		got.ComputeParallel(g, r.Intn(40), r.Intn(40), radius)
+		got.ComputeParallel(g, px, py, radius)
+		sameVisible(t, got, want, "grid %d from %d,%d radius %d", i, px, py, radius)
+		for k := range want.distances {
+			if got.distances[k] != want.distances[k] {
+				x, y := unpack(k)
+				t.Fatalf("grid %d: distance of %d,%d is %d, want %d", i, x, y, got.distances[k], want.distances[k])
+			}
+		}
+	}
+}
+
+// benchmarkGrid is a large map with scattered pillars for benchmarking computes at radius 200
+func benchmarkGrid() *BoolGrid {
+	return randomGrid(rand.New(rand.NewSource(1)), 401, 401, 50)
+}
+
+func BenchmarkCompute(b *testing.B) {
+	g, v := benchmarkGrid(), New()
+	v.Compute(g, 200, 200, 200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Compute(g, 200, 200, 200)
+	}
+}
+
+// BenchmarkComputeAll lights a room of the benchmark grid with a torch at each corner, one in the middle and a few
+// smaller ones in between
+func BenchmarkComputeAll(b *testing.B) {
+	g := benchmarkGrid()
+	torches := []Source{
+		{X: 120, Y: 120, R: 40}, {X: 280, Y: 120, R: 40}, {X: 120, Y: 280, R: 40}, {X: 280, Y: 280, R: 40},
+		{X: 200, Y: 200, R: 60}, {X: 200, Y: 140, R: 20}, {X: 140, Y: 200, R: 20}, {X: 260, Y: 200, R: 20},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ComputeAll(g, torches)
+	}
+}
+
+func BenchmarkComputeParallel(b *testing.B) {
+	g, v := benchmarkGrid(), New()
+	v.ComputeParallel(g, 200, 200, 200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.ComputeParallel(g, 200, 200, 200)
+	}
+}
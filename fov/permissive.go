@@ -0,0 +1,96 @@
+package fov
+
+// ComputePermissive finds the visible tiles with a permissive test, which some players prefer to the corners of
+// shadowcasting: a tile is visible if any straight line from some point of the player's tile to some point of the
+// target tile gets there without passing through an opaque tile. level decides how many points of each tile are
+// tried, a level by level grid of evenly spaced points, so level 1 only connects tile centers while higher levels
+// come closer and closer to any line at all, giving a rounder and more generous field of view with every level.
+// A line that passes exactly through the corner where four tiles meet only crosses the two diagonal ones, unless the
+// View uses the Strict CornerRule and the other two are both opaque, which blocks the line. Each pair of points is
+// tried for each tile, so the cost grows with the fourth power of level, and higher levels are best kept to small
+// radii. Levels below 1 are treated as 1, and only opacity is considered, transparency isn't
+func (v *View) ComputePermissive(grid GridMap, px, py, radius, level int) *View {
+	if level < 1 {
+		level = 1
+	}
+	s := v.newScan(grid, px, py, radius)
+	v.begin(&s)
+	for dy := -s.depth; dy <= s.depth; dy++ {
+		for dx := -s.depth; dx <= s.depth; dx++ {
+			if dx == 0 && dy == 0 || !s.inRange(dx, dy) {
+				continue
+			}
+			if x, y := s.at(dx, dy); s.grid.InBounds(x, y) && s.anyLine(dx, dy, level) {
+				v.mark(&s, x, y, dx, dy)
+			}
+		}
+	}
+	v.memorize()
+	return v
+}
+
+// anyLine reports whether any of the lines between the sample points of the player's tile and those of the tile
+// offset dx,dy from them is unobstructed. Coordinates are scaled by 2*n so that every sample point, and every tile
+// edge, lies on a whole number. Tile 0 spans -n to n, and its sample points lie strictly in between
+func (s *scan) anyLine(dx, dy, n int) bool {
+	for ay := 0; ay < n; ay++ {
+		for ax := 0; ax < n; ax++ {
+			for by := 0; by < n; by++ {
+				for bx := 0; bx < n; bx++ {
+					if s.sightline(2*ax+1-n, 2*ay+1-n, 2*n*dx+2*bx+1-n, 2*n*dy+2*by+1-n, n, dx, dy) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// sightline walks every tile the line from the scaled point ax,ay in the player's tile to bx,by in the tile offset
+// tx,ty from them passes through, reporting whether none of the tiles in between are opaque
+func (s *scan) sightline(ax, ay, bx, by, n, tx, ty int) bool {
+	sx, sy := 1, 1
+	if bx < ax {
+		sx = -1
+	}
+	if by < ay {
+		sy = -1
+	}
+	lx, ly := abs(bx-ax), abs(by-ay)
+	x, y := 0, 0
+	for x != tx || y != ty {
+		// Find which tile edge the line reaches first, the vertical one (at a distance of ex along x) or the
+		// horizontal one (ey along y), comparing the fractions ex/lx and ey/ly without dividing
+		ex, ey := abs((2*x+sx)*n-ax), abs((2*y+sy)*n-ay)
+		switch cx, cy := ex*ly, ey*lx; {
+		case lx == 0:
+			y += sy
+		case ly == 0:
+			x += sx
+		case cx < cy:
+			x += sx
+		case cy < cx:
+			y += sy
+		default:
+			// Exactly through a corner, which the Strict rule seals when the two tiles either side of it are walls
+			if s.corners == Strict && s.wallAt(x+sx, y) && s.wallAt(x, y+sy) {
+				return false
+			}
+			x, y = x+sx, y+sy
+		}
+		if x == tx && y == ty {
+			return true
+		}
+		if s.wallAt(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// wallAt reports whether the tile offset dx,dy from the player is a wall
+func (s *scan) wallAt(dx, dy int) bool {
+	x, y := s.at(dx, dy)
+	return s.wall(x, y)
+}
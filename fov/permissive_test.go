@@ -0,0 +1,34 @@
+package fov
+
+import "testing"
+
+func TestComputePermissiveAroundPillar(t *testing.T) {
+	g := NewBoolGrid(21, 21)
+	g.SetOpaque(12, 10, true)
+	want := New().Compute(g, 10, 10, 9)
+	// Higher levels are at least as generous as lower ones
+	extra := 0
+	for level := 2; level <= 4; level++ {
+		v := New().ComputePermissive(g, 10, 10, 9, level)
+		for k := range want.Visible {
+			if _, ok := v.Visible[k]; !ok {
+				x, y := unpack(k)
+				t.Fatalf("level %d: Compute sees %d,%d but ComputePermissive doesn't", level, x, y)
+			}
+		}
+		// Everything extra lies in the shadow of the pillar, which still hides the tiles straight behind it
+		n := 0
+		for k := range v.Visible {
+			if _, ok := want.Visible[k]; !ok {
+				if x, y := unpack(k); x <= 12 || y == 10 {
+					t.Errorf("level %d: %d,%d is extra but not in the shadow of the pillar", level, x, y)
+				}
+				n++
+			}
+		}
+		if n == 0 || n < extra {
+			t.Errorf("level %d reveals %d tiles more than Compute, after %d at the level before", level, n, extra)
+		}
+		extra = n
+	}
+}
@@ -0,0 +1,30 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComputeRaycastAgainstCompute(t *testing.T) {
+	g := NewBoolGrid(41, 41)
+	for r := 0; r <= 20; r++ {
+		sameVisible(t, New().ComputeRaycast(g, 20, 20, r), New().Compute(g, 20, 20, r), "open ground radius %d", r)
+	}
+	// Near walls the rays find less, but never anything shadowcasting doesn't
+	rnd := rand.New(rand.NewSource(1))
+	missed := 0
+	for i := 0; i < 100; i++ {
+		g := randomGrid(rnd, 30, 30, 6)
+		ray, want := New().ComputeRaycast(g, 15, 15, 12), New().Compute(g, 15, 15, 12)
+		for k := range ray.Visible {
+			if _, ok := want.Visible[k]; !ok {
+				x, y := unpack(k)
+				t.Fatalf("grid %d: the rays see %d,%d but Compute doesn't", i, x, y)
+			}
+		}
+		missed += len(want.Visible) - len(ray.Visible)
+	}
+	if missed == 0 {
+		t.Error("the rays should leave gaps near walls that Compute sees")
+	}
+}
@@ -0,0 +1,46 @@
+package fov
+
+// ComputeInRect is Compute restricted to the rectangle minX,minY to maxX,maxY, inclusive on all sides, such as the
+// currently loaded chunk of a much larger world. Tiles outside the rectangle are never part of the result, the
+// player's own tile included, while tiles inside it are exactly those Compute would find. The octants are only
+// scanned as deep as the rectangle reaches, and not at all when they miss it entirely, so the work is bounded by
+// the rectangle no matter how large the radius is. On an IndexedGrid the rectangle is compared against the wrapped
+// coordinates, and every octant is scanned in full
+func (v *View) ComputeInRect(grid GridMap, px, py, radius int, minX, minY, maxX, maxY int) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.rect, s.minX, s.minY, s.maxX, s.maxY = true, minX, minY, maxX, maxY
+	v.run(&s)
+	return v
+}
+
+// reach returns how deep the given octant needs to be scanned, out of the full depth, or 0 when it doesn't need to
+// be scanned at all
+func (s *scan) reach(depth, oct int) int {
+	if s.octants != 0 && s.octants&(1<<uint(oct)) == 0 {
+		return 0
+	}
+	if !s.rect || s.index != nil {
+		return depth
+	}
+	// Find the furthest the rectangle extends along the octant's distance and height axes, undoing the flips and
+	// transposition of distHeightXY. A rectangle entirely behind the octant, or entirely on the wrong side of it,
+	// can't contain any of its tiles
+	dLow, dHigh := s.minX-s.px, s.maxX-s.px
+	hLow, hHigh := s.minY-s.py, s.maxY-s.py
+	if oct&0x4 > 0 {
+		dLow, dHigh, hLow, hHigh = hLow, hHigh, dLow, dHigh
+	}
+	if oct&0x1 > 0 {
+		dHigh = -dLow
+	}
+	if oct&0x2 > 0 {
+		hHigh = -hLow
+	}
+	if hHigh < 0 {
+		return 0
+	}
+	if dHigh < depth {
+		return dHigh
+	}
+	return depth
+}
@@ -0,0 +1,95 @@
+package fov
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Colors used by Image
+var (
+	floorColor  = color.RGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff}
+	wallColor   = color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff}
+	originColor = color.RGBA{R: 0xff, A: 0xff}
+	hiddenColor = color.RGBA{A: 0xff}
+)
+
+// Render draws the square of the grid within r tiles of px,py as text, one line per row, which is handy for
+// debugging and for pasting the shape of a field of view into a bug report. The player is drawn as '@', visible
+// opaque tiles as '#', visible transparent tiles as '.' and everything that isn't visible as a space
+func (v *View) Render(grid GridMap, px, py, r int) string {
+	index, _ := grid.(IndexedGrid)
+	var b strings.Builder
+	for y := py - r; y <= py+r; y++ {
+		for x := px - r; x <= px+r; x++ {
+			mapx, mapy := x, y
+			if index != nil {
+				mapx, mapy = index.Index(x, y)
+			}
+			switch {
+			case x == px && y == py:
+				b.WriteByte('@')
+			case !v.IsVisible(mapx, mapy):
+				b.WriteByte(' ')
+			case opaque(grid, mapx, mapy):
+				b.WriteByte('#')
+			default:
+				b.WriteByte('.')
+			}
+		}
+		if y < py+r {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// Image is the graphical counterpart to Render, producing an *image.RGBA of the same square with one pixel per tile,
+// for golden image tests or simply eyeballing the shape of a field of view. The player is red, visible opaque tiles
+// are dark gray, visible transparent tiles are light gray and everything that isn't visible is black. The top left
+// pixel is the tile at px-r,py-r
+func (v *View) Image(grid GridMap, px, py, r int) image.Image {
+	index, _ := grid.(IndexedGrid)
+	img := image.NewRGBA(image.Rect(0, 0, 2*r+1, 2*r+1))
+	for y := py - r; y <= py+r; y++ {
+		for x := px - r; x <= px+r; x++ {
+			mapx, mapy := x, y
+			if index != nil {
+				mapx, mapy = index.Index(x, y)
+			}
+			c := hiddenColor
+			switch {
+			case x == px && y == py:
+				c = originColor
+			case !v.IsVisible(mapx, mapy):
+			case opaque(grid, mapx, mapy):
+				c = wallColor
+			default:
+				c = floorColor
+			}
+			img.SetRGBA(x-px+r, y-py+r, c)
+		}
+	}
+	return img
+}
+
+// BoolMask returns the visibility of the square of tiles within r of px,py as a dense slice stored row by row, for
+// uploading to a GPU or for bit twiddling. The square is width by height tiles with offsetX,offsetY in its top left
+// corner, so the tile at x,y is visible when mask[(y-offsetY)*width+x-offsetX] is true. Called with the position and
+// radius of a compute, the square holds everything that compute could see. A negative radius has an empty mask
+func (v *View) BoolMask(px, py, r int) (mask []bool, width, height, offsetX, offsetY int) {
+	if r < 0 {
+		return nil, 0, 0, px, py
+	}
+	width, height, offsetX, offsetY = 2*r+1, 2*r+1, px-r, py-r
+	mask = make([]bool, width*height)
+	for k := range v.Visible {
+		x, y := unpack(k)
+		if x -= offsetX; x >= 0 && x < width {
+			if y -= offsetY; y >= 0 && y < height {
+				mask[y*width+x] = true
+			}
+		}
+	}
+	return mask, width, height, offsetX, offsetY
+}
@@ -0,0 +1,12 @@
+package fov
+
+// ComputeRing marks a tile visible only when its distance from the player lies within [rMin, rMax], producing a
+// ring of vision with a hole in the middle. Tiles inside the hole still cast shadows as usual, they just can't be
+// seen themselves. By default the player's own tile is only part of the result when rMin is 0, a View created
+// WithRingOrigin always sees it
+func (v *View) ComputeRing(grid GridMap, px, py, rMin, rMax int) *View {
+	s := v.newScan(grid, px, py, rMax)
+	s.minRadius, s.hideOrigin = rMin, rMin > 0 && !v.ringOrigin
+	v.run(&s)
+	return v
+}
@@ -0,0 +1,30 @@
+package fov
+
+import "testing"
+
+func TestComputeRingHole(t *testing.T) {
+	g := NewBoolGrid(31, 31)
+	const px, py, rMin, rMax = 15, 15, 3, 7
+	v := New().ComputeRing(g, px, py, rMin, rMax)
+	for y := 0; y < 31; y++ {
+		for x := 0; x < 31; x++ {
+			if x == px && y == py {
+				continue
+			}
+			d := distSq(x, y, px, py)
+			want := d >= rMin*rMin && d <= rMax*rMax
+			if v.IsVisible(x, y) != want {
+				t.Fatalf("%d,%d at squared distance %d: visible is %v, want %v", x, y, d, v.IsVisible(x, y), want)
+			}
+		}
+	}
+	if v.IsVisible(px, py) {
+		t.Error("the origin should be hidden by default when rMin > 0")
+	}
+	if !New(WithRingOrigin()).ComputeRing(g, px, py, rMin, rMax).IsVisible(px, py) {
+		t.Error("the origin should be visible WithRingOrigin")
+	}
+	if !New().ComputeRing(g, px, py, 0, rMax).IsVisible(px, py) {
+		t.Error("the origin should be visible when rMin is 0")
+	}
+}
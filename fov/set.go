@@ -0,0 +1,93 @@
+package fov
+
+// Merge adds every cell visible in other to the visible set of v, so that v sees anything either of them could.
+// Only the visible sets are combined, other does not change
+func (v *View) Merge(other *View) {
+	if v.Visible == nil {
+		v.Visible = make(CellSet, len(other.Visible))
+	}
+	for k := range other.Visible {
+		v.Visible[k] = struct{}{}
+	}
+}
+
+// Union returns a new View whose visible set contains every cell visible in any of views. None of the provided
+// views are modified
+func Union(views ...*View) *View {
+	// The largest input is a lower bound on the size of the result, so start there to avoid most of the rehashing
+	size := 0
+	for _, o := range views {
+		if len(o.Visible) > size {
+			size = len(o.Visible)
+		}
+	}
+	u := &View{Visible: make(CellSet, size)}
+	for _, o := range views {
+		u.Merge(o)
+	}
+	return u
+}
+
+// Intersect returns a new View containing only the cells visible in both v and other. Intersecting with a View
+// that has nothing visible produces an empty View. Neither v nor other is modified
+func (v *View) Intersect(other *View) *View {
+	// Walk whichever set is smaller and probe the larger one
+	small, large := v.Visible, other.Visible
+	if len(large) < len(small) {
+		small, large = large, small
+	}
+	i := &View{Visible: make(CellSet, len(small))}
+	for k := range small {
+		if _, ok := large[k]; ok {
+			i.Visible[k] = struct{}{}
+		}
+	}
+	return i
+}
+
+// Difference returns a new View containing the cells visible in v but not in other, for instance the tiles newly
+// revealed since a previous turn. The difference of a View with itself is empty. Neither v nor other is modified
+func (v *View) Difference(other *View) *View {
+	d := &View{Visible: make(CellSet)}
+	for k := range v.Visible {
+		if _, ok := other.Visible[k]; !ok {
+			d.Visible[k] = struct{}{}
+		}
+	}
+	return d
+}
+
+// RecomputeDiff performs a Compute on v and returns the cells that have come into view (gained) and those that have
+// dropped out of view (lost) compared to whatever v held before, e.g. after a door opens. It is equivalent to taking
+// a Snapshot before the compute and calling Delta after it, but the previous visible set is kept around by v to be
+// refilled next time rather than copied, so nothing is allocated for it once v has been through a call or two. Note
+// that this means Visible is a different set after the call
+func RecomputeDiff(v *View, grid GridMap, px, py, r int) (gained, lost []Point) {
+	if v.spare == nil {
+		v.spare = make(CellSet, len(v.Visible))
+	}
+	// begin empties the visible set before the compute fills it, so the spare takes its place
+	previous := v.Visible
+	v.Visible, v.spare = v.spare, previous
+	v.Compute(grid, px, py, r)
+	return v.Delta(&View{Visible: previous})
+}
+
+// Delta compares v against the View of a previous frame, returning the cells that have come into view since then
+// (entered) and the cells that have dropped out of view (left). The cost is proportional to the number of visible
+// cells, not the size of the map
+func (v *View) Delta(previous *View) (entered, left []Point) {
+	for k := range v.Visible {
+		if _, ok := previous.Visible[k]; !ok {
+			x, y := unpack(k)
+			entered = append(entered, Point{x, y})
+		}
+	}
+	for k := range previous.Visible {
+		if _, ok := v.Visible[k]; !ok {
+			x, y := unpack(k)
+			left = append(left, Point{x, y})
+		}
+	}
+	return entered, left
+}
@@ -0,0 +1,75 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := randomGrid(r, 40, 40, 5)
+	var views []*View
+	for i := 0; i < 4; i++ {
+		views = append(views, New().Compute(g, r.Intn(40), r.Intn(40), 3+r.Intn(8)))
+	}
+	counts := make([]int, len(views))
+	for i, o := range views {
+		counts[i] = o.Count()
+	}
+
+	u := Union(views...)
+	merged := New()
+	for _, o := range views {
+		merged.Merge(o)
+	}
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			seen := false
+			for _, o := range views {
+				seen = seen || o.IsVisible(x, y)
+			}
+			if u.IsVisible(x, y) != seen {
+				t.Fatalf("Union: %d,%d visible is %v, visible to any input is %v", x, y, u.IsVisible(x, y), seen)
+			}
+			if merged.IsVisible(x, y) != seen {
+				t.Fatalf("Merge: %d,%d visible is %v, visible to any input is %v", x, y, merged.IsVisible(x, y), seen)
+			}
+		}
+	}
+	for i, o := range views {
+		if o.Count() != counts[i] {
+			t.Errorf("input %d changed from %d to %d visible cells", i, counts[i], o.Count())
+		}
+	}
+}
+
+func TestDeltaAfterOneStep(t *testing.T) {
+	g := NewBoolGrid(40, 40)
+	before := New().Compute(g, 20, 20, 6)
+	after := New().Compute(g, 21, 20, 6)
+	entered, left := after.Delta(before)
+
+	for _, p := range entered {
+		if !after.Contains(p) || before.Contains(p) {
+			t.Errorf("%v entered but wasn't newly visible", p)
+		}
+		if p.X <= 20 {
+			t.Errorf("%v entered behind the direction of the step", p)
+		}
+	}
+	for _, p := range left {
+		if after.Contains(p) || !before.Contains(p) {
+			t.Errorf("%v left but wasn't newly hidden", p)
+		}
+		if p.X >= 21 {
+			t.Errorf("%v left ahead of the direction of the step", p)
+		}
+	}
+	// Each of the 13 rows of the disk gains one tile at its east end and loses one at its west end
+	if len(entered) != 13 || len(left) != 13 {
+		t.Errorf("got %d entered and %d left, want 13 of each", len(entered), len(left))
+	}
+	if total := len(after.Difference(before).Visible) + len(before.Difference(after).Visible); total != 26 {
+		t.Errorf("Delta disagrees with Difference, which changed %d cells", total)
+	}
+}
@@ -0,0 +1,51 @@
+package fov
+
+// Disk returns every tile within radius r of px,py, ignoring obstacles entirely, row by row from the top. The radius
+// is measured just as Compute measures it by default, so on a map without any walls Compute reveals exactly these
+// tiles. A negative radius has no tiles
+func Disk(px, py, r int) []Point {
+	var tiles []Point
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if Euclidean.inRadius(dx, dy, r) {
+				tiles = append(tiles, Point{px + dx, py + dy})
+			}
+		}
+	}
+	return tiles
+}
+
+// Ring returns the outline of the Disk of the same radius, the tiles within it with at least one orthogonal neighbor
+// that isn't, row by row from the top
+func Ring(px, py, r int) []Point {
+	var tiles []Point
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if !Euclidean.inRadius(dx, dy, r) {
+				continue
+			}
+			for _, n := range neighbors {
+				if !Euclidean.inRadius(dx+n[0], dy+n[1], r) {
+					tiles = append(tiles, Point{px + dx, py + dy})
+					break
+				}
+			}
+		}
+	}
+	return tiles
+}
+
+// MaxVisible returns how many tiles Compute could possibly see from px,py with radius r if there were no walls at
+// all, the tiles of the Disk that are in bounds of grid. Comparing it with Count of the actual result gives a measure
+// of how enclosed a spot is
+func MaxVisible(grid GridMap, px, py, r int) int {
+	n := 0
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if Euclidean.inRadius(dx, dy, r) && grid.InBounds(px+dx, py+dy) {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,12 @@
+package fov
+
+// ComputeSquare limits the field of view to the square of tiles no more than radius away from the player along
+// either axis, rather than a circle, so an open map reveals a full (2*radius+1) by (2*radius+1) square. This is the
+// same shape as Compute measured with Chebyshev distance, but no distance is measured at all, the scan simply stops
+// at the edge of the square, which makes it the cheapest shape there is
+func (v *View) ComputeSquare(grid GridMap, px, py, radius int) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.square = true
+	v.run(&s)
+	return v
+}
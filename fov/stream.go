@@ -0,0 +1,39 @@
+package fov
+
+// streamBuffer is how many tiles Stream can find ahead of the receiver before it waits for the receiver to catch up
+const streamBuffer = 64
+
+// Stream performs a Compute on its own goroutine, sending each tile on the returned channel as soon as it is found
+// visible, so that a very large field of view can be processed while it is still being computed. Tiles are sent in
+// no particular order, but the player's tile is always first and no tile is sent twice. The channel is closed once
+// the compute is done, at which point v holds the complete result as usual and OnReveal, which is still called for
+// every tile as well, is back to what it was. Until then v must not be used at all. To stop receiving early, close
+// done: the compute then finishes without sending anything more, and v can be used again as soon as the channel is
+// closed, whatever tiles are still buffered in it can be ignored. With a nil done the channel has to be drained to
+// the end, or the compute is left stuck waiting to send
+func (v *View) Stream(grid GridMap, px, py, radius int, done <-chan struct{}) <-chan Point {
+	ch := make(chan Point, streamBuffer)
+	go func() {
+		defer close(ch)
+		onReveal := v.OnReveal
+		defer func() {
+			v.OnReveal = onReveal
+		}()
+		abandoned := false
+		v.OnReveal = func(x, y int) {
+			if onReveal != nil {
+				onReveal(x, y)
+			}
+			if abandoned {
+				return
+			}
+			select {
+			case ch <- Point{x, y}:
+			case <-done:
+				abandoned = true
+			}
+		}
+		v.Compute(grid, px, py, radius)
+	}()
+	return ch
+}
@@ -0,0 +1,52 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 60, 60, 6)
+	want := New().Compute(g, 30, 30, 25)
+	v := New()
+	got := New()
+	for p := range v.Stream(g, 30, 30, 25, nil) {
+		if got.IsVisible(p.X, p.Y) {
+			t.Fatalf("%d,%d was sent twice", p.X, p.Y)
+		}
+		got.Visible.Add(p.X, p.Y)
+	}
+	sameVisible(t, got, want, "tiles sent")
+	sameVisible(t, v, want, "result")
+}
+
+func TestStreamAbandoned(t *testing.T) {
+	g := randomGrid(rand.New(rand.NewSource(1)), 60, 60, 6)
+	v := New()
+	revealed := 0
+	v.OnReveal = func(x, y int) {
+		revealed++
+	}
+	done := make(chan struct{})
+	ch := v.Stream(g, 30, 30, 25, done)
+	for i := 0; i < 3; i++ {
+		<-ch
+	}
+	close(done)
+	// Only what was sent around the time done was closed is left to read, not the rest of the tiles
+	left := 0
+	for range ch {
+		left++
+	}
+	if left > 2*streamBuffer {
+		t.Errorf("%d tiles were sent after done was closed", left)
+	}
+	sameVisible(t, v, New().Compute(g, 30, 30, 25), "result")
+	if revealed != v.Count() {
+		t.Errorf("OnReveal was called %d times for %d tiles", revealed, v.Count())
+	}
+	v.Compute(g, 10, 10, 5)
+	if revealed != len(New().Compute(g, 30, 30, 25).Visible)+v.Count() {
+		t.Error("OnReveal should be restored once the stream is done")
+	}
+}
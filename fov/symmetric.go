@@ -0,0 +1,89 @@
+package fov
+
+// ComputeSymmetric computes the field of view using symmetric shadowcasting, as described by Albert Ford
+// (https://www.albertford.com/shadowcasting/), instead of the recursive shadowcasting used by Compute. Floor tiles
+// are only lit when their center is within the visible range of slopes, which guarantees that if one floor tile can
+// see another then the reverse is also true. Walls are lit whenever any part of them is in view, so that the edges of
+// a room still show up. The results otherwise follow the same rules as Compute. To use symmetric shadowcasting for
+// every compute, including the other variations of Compute, construct the View with the WithSymmetric option
+func (v *View) ComputeSymmetric(grid GridMap, px, py, radius int) *View {
+	s := v.newScan(grid, px, py, radius)
+	s.symmetric = true
+	v.run(&s)
+	return v
+}
+
+// fraction is an exact slope of num/den, with den always positive. Symmetric shadowcasting depends on comparing
+// slopes precisely, tile centers sit exactly on the boundaries of the ranges, so floating point won't do
+type fraction struct {
+	num, den int
+}
+
+// quadrant scans a single row of one of the four quadrants around the player, recursing into the rows further out
+// in the same way fov does for octants. Quadrants 0 through 3 face north, east, south and west respectively, and
+// col runs across each row from one diagonal to the other
+func (v *View) quadrant(s *scan, q, depth int, start, end fraction) {
+	if depth > s.depth {
+		return
+	}
+
+	// The first and last columns touched by the range of slopes, with ties rounded inward
+	minCol := floorDiv(2*depth*start.num+start.den, 2*start.den)
+	maxCol := ceilDiv(2*depth*end.num-end.den, 2*end.den)
+
+	// prev tracks whether the previous tile in the row was a wall, and whether there was a previous tile at all
+	prevWall, started := false, false
+	for col := minCol; col <= maxCol; col++ {
+		dx, dy := quadrantXY(0, 0, depth, col, q)
+		mapx, mapy := s.at(dx, dy)
+		wall := s.blocks(mapx, mapy, dx, dy)
+
+		// Walls are lit as soon as they are touched, floors only when their center lies within the range of slopes
+		if wall || (col*start.den >= depth*start.num && col*end.den <= depth*end.num) {
+			if s.grid.InBounds(mapx, mapy) && s.inRange(dx, dy) && s.clear(dx, dy) {
+				v.mark(s, mapx, mapy, dx, dy)
+			}
+		}
+
+		if started && prevWall && !wall {
+			// Leaving a wall, so the visible range for the rest of this row starts at this tile's leading edge
+			start = fraction{2*col - 1, 2 * depth}
+		}
+		if started && !prevWall && wall {
+			// Entering a wall, so everything seen so far in this row continues into the next row up to this tile
+			v.quadrant(s, q, depth+1, start, fraction{2*col - 1, 2 * depth})
+		}
+		prevWall, started = wall, true
+	}
+	if started && !prevWall {
+		v.quadrant(s, q, depth+1, start, end)
+	}
+}
+
+// quadrantXY is the quadrant equivalent of distHeightXY, turning a row depth and column into map coordinates
+func quadrantXY(px, py, depth, col, q int) (int, int) {
+	switch q {
+	case 0:
+		return px + col, py - depth
+	case 1:
+		return px + depth, py + col
+	case 2:
+		return px + col, py + depth
+	default:
+		return px - depth, py + col
+	}
+}
+
+// floorDiv divides a by a positive b, rounding toward negative infinity rather than toward zero
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// ceilDiv divides a by a positive b, rounding toward positive infinity
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
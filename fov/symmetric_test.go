@@ -0,0 +1,30 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComputeSymmetricMutualVisibility(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const w, h, radius = 16, 16, 8
+	for i := 0; i < 20; i++ {
+		g := randomGrid(r, w, h, 4)
+		views := make(map[Point]*View)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if !g.IsOpaque(x, y) {
+					views[Point{x, y}] = New().ComputeSymmetric(g, x, y, radius)
+				}
+			}
+		}
+		for a, va := range views {
+			for b, vb := range views {
+				if va.Contains(b) != vb.Contains(a) {
+					t.Fatalf("grid %d: %v sees %v is %v, but %v sees %v is %v\n%s", i, a, b, va.Contains(b), b, a,
+						vb.Contains(a), va.Render(g, a.X, a.Y, radius))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package fov
+
+// Source is a single viewer or light source at X,Y that sees up to R tiles away. ID identifies it to the caller,
+// for instance as the index of a squad member
+type Source struct {
+	X, Y, R int
+	ID      int
+}
+
+// ComputeTeam computes everything seen by any of the sources, for a squad that shares its vision, while keeping
+// track of which of them sees each tile, see SeenBy. The position of every source is visible, and Origin reports the
+// first of them. No sources see nothing at all
+func (v *View) ComputeTeam(grid GridMap, sources []Source) *View {
+	if v.seenBy == nil {
+		v.seenBy = make(map[int64][]int)
+	}
+	v.computeSources(grid, sources, true)
+	return v
+}
+
+// ComputeAll returns a new View holding everything seen by any of the sources, e.g. every torch lit in a room,
+// created with the given options. The result is the same as the union of calling Compute for each source in turn,
+// but the sources share a single View and its storage between them. The position of every source is visible, and
+// Origin reports the first of them
+func ComputeAll(grid GridMap, sources []Source, opts ...Option) *View {
+	v := New(opts...)
+	v.computeSources(grid, sources, false)
+	return v
+}
+
+// computeSources sweeps from each of the sources in turn into the one visible set, attributing the tiles to the
+// sources they're seen by when team is set
+func (v *View) computeSources(grid GridMap, sources []Source, team bool) {
+	if len(sources) == 0 {
+		v.Reset()
+		return
+	}
+	for i, src := range sources {
+		s := v.newScan(grid, src.X, src.Y, src.R)
+		s.team, s.source = team, src.ID
+		if i == 0 {
+			v.begin(&s)
+		} else {
+			x, y := s.at(0, 0)
+			v.mark(&s, x, y, 0, 0)
+		}
+		v.sweep(&s)
+	}
+	v.memorize()
+}
+
+// SeenBy returns the IDs of the sources of the most recent ComputeTeam that see the tile at x,y, in the order the
+// sources were given. Tiles that aren't visible, and tiles after any other compute, aren't seen by anyone
+func (v *View) SeenBy(x, y int) []int {
+	return append([]int(nil), v.seenBy[key(x, y)]...)
+}
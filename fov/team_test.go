@@ -0,0 +1,22 @@
+package fov
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComputeAllIsUnionOfComputes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	configs := [][]Option{nil, {WithBlindInWalls()}, {WithCornerRule(Strict)}, {WithMetric(Chebyshev)}}
+	for i := 0; i < 100; i++ {
+		g := randomGrid(r, 40, 40, 2+r.Intn(6))
+		opts := configs[i%len(configs)]
+		sources := make([]Source, 1+r.Intn(5))
+		views := make([]*View, len(sources))
+		for j := range sources {
+			sources[j] = Source{X: r.Intn(40), Y: r.Intn(40), R: r.Intn(12), ID: j}
+			views[j] = New(opts...).Compute(g, sources[j].X, sources[j].Y, sources[j].R)
+		}
+		sameVisible(t, ComputeAll(g, sources, opts...), union(views...), "grid %d sources %v", i, sources)
+	}
+}
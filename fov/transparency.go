@@ -0,0 +1,49 @@
+package fov
+
+// TransparentGrid is a GridMap whose tiles can be partially transparent, like fog or smoke, which dims vision rather
+// than blocking it outright. Transmittance is the fraction of light a tile lets through, from 1 for clear air down to
+// 0 for something that can't be seen through at all. When the grid passed to a compute implements it, each tile
+// along the line from the player adds 1 - Transmittance to the accumulated opacity of that line, and vision stops
+// once the accumulated opacity reaches 1. Lightly hazy tiles with a transmittance of 0.7 each add 0.3, so three of
+// them in a row can be seen past but a fourth casts a shadow just like a wall. IsOpaque is still honored, an opaque
+// tile blocks regardless of its transmittance
+type TransparentGrid interface {
+	GridMap
+	Transmittance(x, y int) float64
+}
+
+// blocks reports whether the tile at x,y, offset dx,dy from the player, stops vision for the tiles beyond it
+func (s *scan) blocks(x, y, dx, dy int) bool {
+	if s.wall(x, y) || s.pinched(dx, dy) {
+		return true
+	}
+	return s.transparent != nil && s.haze(dx, dy, true) >= 1
+}
+
+// clear reports whether enough light reaches the tile offset dx,dy from the player for it to be seen at all
+func (s *scan) clear(dx, dy int) bool {
+	if s.pinched(dx, dy) {
+		return false
+	}
+	if s.transparent != nil && s.haze(dx, dy, false) >= 1 {
+		return false
+	}
+	return s.height == nil || s.overlooks(dx, dy)
+}
+
+// haze accumulates the opacity of the tiles on the line from the player to the tile offset dx,dy. The player's own
+// tile never counts, and the target tile only counts when inclusive is set
+func (s *scan) haze(dx, dy int, inclusive bool) float64 {
+	total := 0.0
+	walkLine(0, 0, dx, dy, func(lx, ly int) bool {
+		if (lx == 0 && ly == 0) || (!inclusive && lx == dx && ly == dy) {
+			return true
+		}
+		x, y := s.at(lx, ly)
+		if s.grid.InBounds(x, y) {
+			total += 1 - s.transparent.Transmittance(x, y)
+		}
+		return total < 1
+	})
+	return total
+}
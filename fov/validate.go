@@ -0,0 +1,85 @@
+package fov
+
+import "fmt"
+
+// ValidateGrid probes every tile from minX,minY to maxX,maxY, inclusive, for the kinds of mistakes in a GridMap
+// implementation that make a field of view come out wrong in ways that are hard to trace back. It returns an error
+// describing the first problem found, or nil if there was none. A grid is expected to
+//
+//   - never panic in InBounds, nor in IsOpaque (or Transmittance and Height, if implemented) for tiles in bounds.
+//     Those are the only tiles they are ever asked about
+//   - give the same answer from InBounds and IsOpaque every time it is asked about the same tile
+//   - only report a Transmittance between 0 and 1
+//   - if it is an IndexedGrid, Index tiles that are in bounds to tiles that are also in bounds, and leave the tiles
+//     it returns unchanged when asked to Index them again
+func ValidateGrid(grid GridMap, minX, minY, maxX, maxY int) error {
+	index, _ := grid.(IndexedGrid)
+	transparent, _ := grid.(TransparentGrid)
+	height, _ := grid.(HeightGrid)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			var in, again bool
+			if err := probe("InBounds", x, y, func() { in, again = grid.InBounds(x, y), grid.InBounds(x, y) }); err != nil {
+				return err
+			}
+			if in != again {
+				return fmt.Errorf("fov: InBounds(%d, %d) changed its answer", x, y)
+			}
+			if index != nil {
+				var ix, iy, rx, ry int
+				var wrappedIn bool
+				err := probe("Index", x, y, func() {
+					ix, iy = index.Index(x, y)
+					rx, ry = index.Index(ix, iy)
+					wrappedIn = grid.InBounds(ix, iy)
+				})
+				if err != nil {
+					return err
+				}
+				if in && !wrappedIn {
+					return fmt.Errorf("fov: Index(%d, %d) returned %d, %d which is out of bounds", x, y, ix, iy)
+				}
+				if rx != ix || ry != iy {
+					return fmt.Errorf("fov: Index(%d, %d) returned %d, %d but Index(%d, %d) returned %d, %d", x, y,
+						ix, iy, ix, iy, rx, ry)
+				}
+			}
+			if !in {
+				continue
+			}
+			var opaque bool
+			if err := probe("IsOpaque", x, y, func() { opaque, again = grid.IsOpaque(x, y), grid.IsOpaque(x, y) }); err != nil {
+				return err
+			}
+			if opaque != again {
+				return fmt.Errorf("fov: IsOpaque(%d, %d) changed its answer", x, y)
+			}
+			if transparent != nil {
+				var t float64
+				if err := probe("Transmittance", x, y, func() { t = transparent.Transmittance(x, y) }); err != nil {
+					return err
+				}
+				if !(t >= 0 && t <= 1) {
+					return fmt.Errorf("fov: Transmittance(%d, %d) returned %v, outside of [0, 1]", x, y, t)
+				}
+			}
+			if height != nil {
+				if err := probe("Height", x, y, func() { height.Height(x, y) }); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// probe calls fn, turning a panic into an error that blames the grid method named by method for the tile at x,y
+func probe(method string, x, y int, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("fov: %s(%d, %d) panicked: %v", method, x, y, r)
+		}
+	}()
+	fn()
+	return nil
+}
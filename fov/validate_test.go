@@ -0,0 +1,57 @@
+package fov
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// edgeGrid panics whenever IsOpaque is asked about a tile outside of it, as a grid backed by a bare slice would
+type edgeGrid struct {
+	*BoolGrid
+}
+
+func (g edgeGrid) IsOpaque(x, y int) bool {
+	if !g.InBounds(x, y) {
+		panic(fmt.Sprintf("IsOpaque(%d, %d) is out of bounds", x, y))
+	}
+	return g.BoolGrid.IsOpaque(x, y)
+}
+
+func TestIsOpaqueOnlyInBounds(t *testing.T) {
+	g := edgeGrid{randomGrid(rand.New(rand.NewSource(1)), 12, 9, 4)}
+	if err := ValidateGrid(g, -3, -3, 14, 11); err != nil {
+		t.Fatal(err)
+	}
+	computes := map[string]func(px, py int) *View{
+		"Compute":           func(px, py int) *View { return New().Compute(g, px, py, 15) },
+		"ComputeIterative":  func(px, py int) *View { return New().ComputeIterative(g, px, py, 15) },
+		"ComputePermissive": func(px, py int) *View { return New().ComputePermissive(g, px, py, 15, 2) },
+		"ComputeRaycast":    func(px, py int) *View { return New().ComputeRaycast(g, px, py, 15) },
+		"ComputeOffset":     func(px, py int) *View { return New().ComputeOffset(g, px, py, 15, 0.3, -0.3) },
+		"ComputeSymmetric":  func(px, py int) *View { return New().ComputeSymmetric(g, px, py, 15) },
+		"Strict":            func(px, py int) *View { return New(WithCornerRule(Strict)).Compute(g, px, py, 15) },
+		"BorderOpaque":      func(px, py int) *View { return New(WithBorderOpaque()).Compute(g, px, py, 15) },
+	}
+	for name, compute := range computes {
+		// From every tile, the corners and edges included, so that the scans run off every side of the grid
+		for py := 0; py < 9; py++ {
+			for px := 0; px < 12; px++ {
+				v := compute(px, py)
+				for k := range v.Visible {
+					if x, y := unpack(k); !g.InBounds(x, y) {
+						t.Fatalf("%s from %d,%d: %d,%d is visible outside the grid", name, px, py, x, y)
+					}
+				}
+				for x := -1; x <= 12; x++ {
+					v.EdgeKind(g, x, -1)
+					v.EdgeKind(g, x, py)
+				}
+				v.Enclosed(g)
+				v.VisibleFloors(g)
+				v.VisibleWalls(g)
+				v.Frontier(g)
+			}
+		}
+	}
+}